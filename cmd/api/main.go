@@ -1,19 +1,25 @@
 package main
 
 import (
+	"JourneyBuilder/internal/api"
 	"JourneyBuilder/internal/api/handlers"
 	"JourneyBuilder/internal/knowledge"
 	"JourneyBuilder/internal/logger"
 	"JourneyBuilder/internal/orchestrator"
+	"JourneyBuilder/internal/render"
 	"JourneyBuilder/internal/services"
+	"JourneyBuilder/internal/storage"
 	"JourneyBuilder/internal/validation"
 
+	"context"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
 	"syscall"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/joho/godotenv"
@@ -51,29 +57,54 @@ func main() {
 
 	// Using custom logger from internal/logger.
 	logger.Println("Initializing AI services...")
-	geminiService, err := services.NewGeminiService()
+	llm, err := services.NewProviderFromEnv()
 	if err != nil {
-		log.Fatalf("Failed to initialize Gemini service: %v", err)
+		log.Fatalf("Failed to initialize LLM provider: %v", err)
 	}
-	defer geminiService.Close()
+	defer llm.Close()
 
 	// Initialize knowledge base
-	frameworksPath := filepath.Join("data", "knowledge", "frameworks.json")
-	sequencesPath := filepath.Join("data", "knowledge", "sequence.json")
-	verticalsPath := filepath.Join("data", "knowledge", "verticals.json")
-	kb, err := knowledge.NewKnowledgeBase(frameworksPath, sequencesPath, verticalsPath)
+	kbPaths := knowledge.DefaultPaths(filepath.Join("data", "knowledge"))
+	kb, err := knowledge.NewKnowledgeBase(kbPaths.Frameworks, kbPaths.Sequences, kbPaths.Verticals)
 	if err != nil {
 		logger.Fatalf("Failed to initialize knowledge base: %v", err)
 	}
+	logger.Printf("✓ Loaded %s", kb)
+
+	// Hot-reload knowledge files (KB_RELOAD_INTERVAL, e.g. "5s"; "0" disables)
+	reloadInterval := 5 * time.Second
+	if v := os.Getenv("KB_RELOAD_INTERVAL"); v != "" {
+		if reloadInterval, err = time.ParseDuration(v); err != nil {
+			logger.Fatalf("Invalid KB_RELOAD_INTERVAL %q: %v", v, err)
+		}
+	}
+	if reloadInterval > 0 {
+		go kb.Watch(context.Background(), reloadInterval)
+		logger.Printf("✓ Watching knowledge files every %s", reloadInterval)
+	}
+
+	// Exports and test sends render every email with this footer, so refuse to start without one
+	if err := render.OptionsFromEnv().Validate(); err != nil {
+		logger.Fatalf("Invalid email footer configuration: %v", err)
+	}
 
 	// Initialize validation
 	inputValidator := validation.NewInputValidator()
 	outputValidator := validation.NewOutputValidator()
 
 	// Initialize orchestrator
-	orch := orchestrator.NewOrchestrator(geminiService, kb, inputValidator, outputValidator)
+	orch := orchestrator.NewOrchestrator(llm, kb, inputValidator, outputValidator)
 	handlers.SetOrchestrator(orch)
-	setupGracefulShutdown(geminiService)
+
+	// Drafts and confirmed journeys (JOURNEY_STORE: "bolt" or "memory"; JOURNEY_DB for the bolt file,
+	// which imports the old JOURNEY_STORE_DIR files on start)
+	journeyStore, err := openJourneyStore()
+	if err != nil {
+		logger.Fatalf("Failed to initialize journey store: %v", err)
+	}
+	defer journeyStore.Close()
+	handlers.SetJourneyStore(journeyStore)
+	setupGracefulShutdown(llm, journeyStore)
 
 	router := mux.NewRouter()
 
@@ -91,6 +122,16 @@ func main() {
 	router.HandleFunc("/api/update-delays", handlers.HandleUpdateDelays).Methods("POST", "OPTIONS")
 	router.HandleFunc("/api/confirm-journey", handlers.HandleConfirmJourney).Methods("POST", "OPTIONS")
 	router.HandleFunc("/api/generate-step", handlers.HandleGenerateStep).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/simulate-journey", handlers.HandleSimulateJourney).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/journeys", handlers.HandleSaveDraft).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/journeys", handlers.HandleListJourneys).Methods("GET")
+	router.HandleFunc("/api/journeys/{id}", handlers.HandleGetJourney).Methods("GET")
+	router.HandleFunc("/api/journeys/{id}/versions", handlers.HandleListJourneyVersions).Methods("GET")
+	router.HandleFunc("/api/journeys/{id}/export", handlers.HandleExportJourney).Methods("GET")
+	router.HandleFunc("/api/journeys/{id}/test-send", handlers.HandleTestSend).Methods("POST", "OPTIONS")
+
+	// Versioned API (includes the SSE endpoint /api/v1/chat/stream)
+	api.SetupRoutes(router, orch, kb)
 
 	// Serve static files from public directory (must be last to catch all other routes)
 	router.PathPrefix("/").Handler(http.FileServer(http.Dir("./public")))
@@ -155,14 +196,52 @@ func main() {
 // 	}
 // }
 
-func setupGracefulShutdown(geminiService *services.GeminiService) {
+// openJourneyStore opens the store selected by JOURNEY_STORE, a BoltDB file by default.
+func openJourneyStore() (storage.JourneyStore, error) {
+	switch kind := os.Getenv("JOURNEY_STORE"); kind {
+	case "", "bolt":
+		path := os.Getenv("JOURNEY_DB")
+		if path == "" {
+			path = filepath.Join("data", "journeys.db")
+		}
+		store, err := storage.OpenBolt(path)
+		if err != nil {
+			return nil, err
+		}
+		// Journeys confirmed before the database existed (JOURNEY_STORE_DIR)
+		dir := os.Getenv("JOURNEY_STORE_DIR")
+		if dir == "" {
+			dir = filepath.Join("data", "journeys")
+		}
+		n, err := store.ImportFiles(dir)
+		if err != nil {
+			store.Close()
+			return nil, err
+		}
+		if n > 0 {
+			logger.Printf("✓ Imported %d journey(s) from %s", n, dir)
+		}
+		logger.Printf("✓ Storing journeys in %s", path)
+		return store, nil
+	case "memory":
+		logger.Println("⚠️  Storing journeys in memory; they are lost on restart")
+		return storage.NewMemoryStore(), nil
+	default:
+		return nil, fmt.Errorf("unknown JOURNEY_STORE %q (want bolt or memory)", kind)
+	}
+}
+
+func setupGracefulShutdown(llm services.LLMProvider, store storage.JourneyStore) {
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
 	go func() {
 		<-c
 		logger.Println("\n🛑 Shutting down gracefully...")
-		if err := geminiService.Close(); err != nil {
-			logger.Printf("Error closing Gemini service: %v", err)
+		if err := llm.Close(); err != nil {
+			logger.Printf("Error closing LLM provider: %v", err)
+		}
+		if err := store.Close(); err != nil {
+			logger.Printf("Error closing journey store: %v", err)
 		}
 		log.Println("✓ Cleanup complete")
 		os.Exit(0)
@@ -0,0 +1,45 @@
+// Command kblint checks the knowledge base files for broken references,
+// duplicate keys, missing fields and inconsistent counts.
+//
+// Usage:
+//
+//	go run ./cmd/kblint [-dir data/knowledge] [-strict]
+//
+// It exits 1 when any error is found (or any warning, with -strict), so it
+// can gate content pull requests.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"JourneyBuilder/internal/knowledge"
+)
+
+func main() {
+	dir := flag.String("dir", "data/knowledge", "directory containing frameworks.json, sequence.json and verticals.json")
+	strict := flag.Bool("strict", false, "treat warnings as errors")
+	flag.Parse()
+
+	issues := knowledge.Lint(knowledge.DefaultPaths(*dir))
+
+	errors, warnings := 0, 0
+	for _, issue := range issues {
+		fmt.Println(issue)
+		if issue.Severity == knowledge.SeverityError {
+			errors++
+		} else {
+			warnings++
+		}
+	}
+
+	if len(issues) == 0 {
+		fmt.Printf("✓ %s: no problems found\n", *dir)
+		return
+	}
+	fmt.Printf("\n%d error(s), %d warning(s)\n", errors, warnings)
+	if errors > 0 || (*strict && warnings > 0) {
+		os.Exit(1)
+	}
+}
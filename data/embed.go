@@ -0,0 +1,10 @@
+// Package data embeds the default knowledge base files so the server can
+// start even when data/knowledge is not present next to the binary.
+package data
+
+import "embed"
+
+// Knowledge holds data/knowledge/*.json as shipped with the build.
+//
+//go:embed knowledge/*.json
+var Knowledge embed.FS
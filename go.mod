@@ -9,6 +9,7 @@ require (
 	github.com/hashicorp/golang-lru/v2 v2.0.7
 	github.com/joho/godotenv v1.5.1
 	github.com/rs/cors v1.11.1
+	go.etcd.io/bbolt v1.4.0
 	google.golang.org/genai v1.40.0
 )
 
@@ -1,20 +1,28 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
+	"time"
 
 	"JourneyBuilder/internal/models"
 	"JourneyBuilder/internal/orchestrator"
+
+	"github.com/gorilla/mux"
 )
 
+// streamGenerationTimeout bounds a single streamed generation.
+const streamGenerationTimeout = 5 * time.Minute
+
 // ChatHandler wires HTTP layer to the orchestrator.
 type ChatHandler struct {
-	orch *orchestrator.Orchestrator
+	orch    *orchestrator.Orchestrator
+	streams *sseHub
 }
 
 func NewChatHandler(orch *orchestrator.Orchestrator) *ChatHandler {
-	return &ChatHandler{orch: orch}
+	return &ChatHandler{orch: orch, streams: newSSEHub()}
 }
 
 // Health returns a simple health check payload.
@@ -61,46 +69,109 @@ func (h *ChatHandler) Chat(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(resp)
 }
 
-// ChatStream handles streaming chat: POST /api/chat/stream
-// For simplicity this example uses Server-Sent Events (SSE) style text streaming.
+// ChatStream handles streaming chat: POST /api/v1/chat/stream
+// It speaks a typed Server-Sent Events protocol (see models.StreamEventType):
+// every event has an id and a JSON payload. A client that drops can reconnect
+// with a Last-Event-ID header (or lastEventId query parameter) to replay the
+// events it missed and continue following the same generation; browsers
+// use ResumeStream instead, since EventSource can only reconnect with GET.
 func (h *ChatHandler) ChatStream(w http.ResponseWriter, r *http.Request) {
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
 	}
 
-	// You can choose a different content type if you implement true SSE.
-	w.Header().Set("Content-Type", "text/event-stream")
-	w.WriteHeader(http.StatusOK)
-	if flusher, ok := w.(http.Flusher); ok {
-		flusher.Flush()
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get("lastEventId")
 	}
 
+	// Decode before writing headers: once the response has started the
+	// server may no longer allow reading the request body.
 	var req models.ChatRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		_, _ = w.Write([]byte("event: error\ndata: invalid request body\n\n"))
-		if flusher, ok := w.(http.Flusher); ok {
-			flusher.Flush()
+	var decodeErr error
+	if lastEventID == "" {
+		decodeErr = json.NewDecoder(r.Body).Decode(&req)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flush(w)
+
+	if lastEventID != "" {
+		streamID, seq, ok := parseLastEventID(lastEventID)
+		if !ok {
+			writeSSEError(w, "invalid Last-Event-ID")
+			return
 		}
+		stream := h.streams.get(streamID)
+		if stream == nil {
+			writeSSEError(w, "stream not found or expired")
+			return
+		}
+		h.streams.serve(w, r, stream, seq)
 		return
 	}
 
-	stream, err := h.orch.ProcessChatRequestStream(r.Context(), &req)
+	if decodeErr != nil {
+		writeSSEError(w, "invalid request body")
+		return
+	}
+
+	// Generation is detached from the request so it survives a dropped
+	// connection and can be resumed.
+	ctx, cancel := context.WithTimeout(context.Background(), streamGenerationTimeout)
+	events, err := h.orch.ProcessChatRequestStream(ctx, &req)
 	if err != nil {
-		_, _ = w.Write([]byte("event: error\ndata: failed to start stream\n\n"))
-		if flusher, ok := w.(http.Flusher); ok {
-			flusher.Flush()
-		}
+		cancel()
+		writeSSEError(w, "failed to start stream: "+err.Error())
 		return
 	}
 
-	flusher, hasFlusher := w.(http.Flusher)
-	for chunk := range stream {
-		// Each chunk is just text; you can adapt the format as needed.
-		_, _ = w.Write([]byte("event: message\n"))
-		_, _ = w.Write([]byte("data: " + chunk + "\n\n"))
-		if hasFlusher {
-			flusher.Flush()
+	stream := h.streams.create()
+	go func() {
+		defer cancel()
+		defer stream.finish()
+		for ev := range events {
+			stream.append(ev)
+		}
+	}()
+
+	h.streams.serve(w, r, stream, 0)
+}
+
+// ResumeStream follows an existing generation: GET /api/v1/chat/stream/{id}
+// This is the URL to hand to a browser EventSource. The first request
+// replays the stream from the start; EventSource's automatic reconnects
+// send Last-Event-ID and continue after the last event received. Unknown
+// or expired streams get a 404, which stops EventSource from retrying.
+func (h *ChatHandler) ResumeStream(w http.ResponseWriter, r *http.Request) {
+	streamID := mux.Vars(r)["id"]
+	seq := 0
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get("lastEventId")
 	}
+	if lastEventID != "" {
+		id, n, ok := parseLastEventID(lastEventID)
+		if !ok || id != streamID {
+			http.Error(w, "invalid Last-Event-ID", http.StatusBadRequest)
+			return
+		}
+		seq = n
+	}
+	stream := h.streams.get(streamID)
+	if stream == nil {
+		http.Error(w, "stream not found or expired", http.StatusNotFound)
+		return
 	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flush(w)
+	h.streams.serve(w, r, stream, seq)
 }
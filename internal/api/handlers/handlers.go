@@ -2,19 +2,38 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
+	"JourneyBuilder/internal/export"
+	"JourneyBuilder/internal/journey"
 	"JourneyBuilder/internal/models"
 	"JourneyBuilder/internal/orchestrator"
+	"JourneyBuilder/internal/simulator"
+	"JourneyBuilder/internal/storage"
+	"JourneyBuilder/internal/testsend"
+
+	"github.com/gorilla/mux"
 )
 
 var globalOrchestrator *orchestrator.Orchestrator
+var globalJourneyStore storage.JourneyStore
 
 // SetOrchestrator sets the global orchestrator instance
 func SetOrchestrator(orch *orchestrator.Orchestrator) {
 	globalOrchestrator = orch
 }
 
+// SetJourneyStore sets the store used for confirmed journeys
+func SetJourneyStore(store storage.JourneyStore) {
+	globalJourneyStore = store
+}
+
 // HandleChat handles chat requests using the global orchestrator
 func HandleChat(w http.ResponseWriter, r *http.Request) {
 	if r.Method == "OPTIONS" {
@@ -43,48 +62,485 @@ func HandleChat(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(resp)
 }
 
-// HandleGenerateJourney is a placeholder for journey generation
+// HandleGenerateJourney generates a complete journey from a brief, without
+// the conversational workflow: POST /api/generate-journey
 func HandleGenerateJourney(w http.ResponseWriter, r *http.Request) {
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
 	}
-	http.Error(w, "Not implemented", http.StatusNotImplemented)
+
+	if globalOrchestrator == nil {
+		http.Error(w, "Orchestrator not initialized", http.StatusInternalServerError)
+		return
+	}
+
+	var brief models.JourneyBrief
+	if err := json.NewDecoder(r.Body).Decode(&brief); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"error": "invalid request body",
+		})
+		return
+	}
+
+	resp, err := globalOrchestrator.GenerateJourney(r.Context(), &brief)
+	if err != nil {
+		writeJourneyError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, resp)
 }
 
-// HandlePreviewJourney is a placeholder for journey preview
+// HandlePreviewJourney returns the skeleton of a journey (subjects, delays and
+// frameworks, no body copy) for a brief: POST /api/preview-journey
 func HandlePreviewJourney(w http.ResponseWriter, r *http.Request) {
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
 	}
-	http.Error(w, "Not implemented", http.StatusNotImplemented)
+
+	if globalOrchestrator == nil {
+		http.Error(w, "Orchestrator not initialized", http.StatusInternalServerError)
+		return
+	}
+
+	var brief models.JourneyBrief
+	if err := json.NewDecoder(r.Body).Decode(&brief); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"error": "invalid request body",
+		})
+		return
+	}
+
+	resp, err := globalOrchestrator.PreviewJourney(r.Context(), &brief)
+	if err != nil {
+		writeJourneyError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, resp)
 }
 
-// HandleUpdateDelays is a placeholder for updating delays
+// HandleUpdateDelays normalizes a journey's edited day delays and warns where
+// they break the template's cadence or duration: POST /api/update-delays
 func HandleUpdateDelays(w http.ResponseWriter, r *http.Request) {
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
 	}
-	http.Error(w, "Not implemented", http.StatusNotImplemented)
+
+	if globalOrchestrator == nil {
+		http.Error(w, "Orchestrator not initialized", http.StatusInternalServerError)
+		return
+	}
+
+	var req models.UpdateDelaysRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"error": "invalid request body",
+		})
+		return
+	}
+
+	resp, err := globalOrchestrator.UpdateDelays(&req)
+	if err != nil {
+		writeJourneyError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, resp)
 }
 
-// HandleConfirmJourney is a placeholder for journey confirmation
+// HandleConfirmJourney validates a journey and stores it as a new immutable
+// version: POST /api/confirm-journey
+// A journey without an id is stored as version 1 of a new journey; sending
+// the id of a confirmed journey stores its next version.
 func HandleConfirmJourney(w http.ResponseWriter, r *http.Request) {
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
 	}
-	http.Error(w, "Not implemented", http.StatusNotImplemented)
+
+	if globalOrchestrator == nil || globalJourneyStore == nil {
+		http.Error(w, "Orchestrator not initialized", http.StatusInternalServerError)
+		return
+	}
+
+	var req struct {
+		Journey *journey.Journey `json:"journey"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"error": "invalid request body",
+		})
+		return
+	}
+
+	problems, warnings := globalOrchestrator.CheckJourney(req.Journey)
+	if len(problems) > 0 {
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+			"error":    "journey failed validation",
+			"problems": problems,
+			"warnings": warnings,
+		})
+		return
+	}
+
+	j := req.Journey
+	confirmedAt := time.Now().UTC()
+	j.ConfirmedAt = &confirmedAt
+	if err := globalJourneyStore.Save(j); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"journey":  j,
+		"warnings": warnings,
+	})
+}
+
+// HandleSaveDraft stores a journey that is not confirmed yet: POST /api/journeys
+// A draft without an id is stored as version 1 of a new journey; sending the
+// id of a stored journey stores its next version. Drafts are only checked for
+// structure; confirming runs the full checks.
+func HandleSaveDraft(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if globalJourneyStore == nil {
+		http.Error(w, "Journey store not initialized", http.StatusInternalServerError)
+		return
+	}
+
+	var req struct {
+		Journey *journey.Journey `json:"journey"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"error": "invalid request body",
+		})
+		return
+	}
+	if err := req.Journey.Validate(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	j := req.Journey
+	j.ConfirmedAt = nil
+	if err := globalJourneyStore.Save(j); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"journey": j,
+	})
+}
+
+// HandleListJourneys summarizes the journeys of a workspace, drafts and
+// confirmed, most recently saved first: GET /api/journeys?workspace=name
+// Without ?workspace the default workspace is listed.
+func HandleListJourneys(w http.ResponseWriter, r *http.Request) {
+	if globalJourneyStore == nil {
+		http.Error(w, "Journey store not initialized", http.StatusInternalServerError)
+		return
+	}
+
+	workspace := r.URL.Query().Get("workspace")
+	journeys, err := globalJourneyStore.ListByWorkspace(workspace)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	if workspace == "" {
+		workspace = storage.DefaultWorkspace
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"workspace": workspace,
+		"journeys":  journeys,
+	})
 }
 
-// HandleGenerateStep is a placeholder for step generation
+// HandleGetJourney returns a stored journey: GET /api/journeys/{id}
+// The latest version is returned unless ?version=N is given.
+func HandleGetJourney(w http.ResponseWriter, r *http.Request) {
+	if globalJourneyStore == nil {
+		http.Error(w, "Journey store not initialized", http.StatusInternalServerError)
+		return
+	}
+
+	version, ok := versionParam(w, r)
+	if !ok {
+		return
+	}
+
+	j, err := globalJourneyStore.Load(mux.Vars(r)["id"], version)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, j)
+}
+
+// HandleListJourneyVersions lists the stored versions of a journey: GET /api/journeys/{id}/versions
+func HandleListJourneyVersions(w http.ResponseWriter, r *http.Request) {
+	if globalJourneyStore == nil {
+		http.Error(w, "Journey store not initialized", http.StatusInternalServerError)
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+	versions, err := globalJourneyStore.ListVersions(id)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"id":       id,
+		"versions": versions,
+	})
+}
+
+// HandleExportJourney returns a stored journey in an email service
+// provider's import format: GET /api/journeys/{id}/export?format=klaviyo
+// (or mailchimp, csv, or eml for a zip of .eml files). The latest version
+// is exported unless ?version=N is given. Branches the format cannot
+// express are listed in X-Export-Warning headers.
+func HandleExportJourney(w http.ResponseWriter, r *http.Request) {
+	if globalJourneyStore == nil {
+		http.Error(w, "Journey store not initialized", http.StatusInternalServerError)
+		return
+	}
+
+	exporter, err := export.New(r.URL.Query().Get("format"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"error": "format must be one of: " + strings.Join(export.Formats, ", "),
+		})
+		return
+	}
+	version, ok := versionParam(w, r)
+	if !ok {
+		return
+	}
+
+	j, err := globalJourneyStore.Load(mux.Vars(r)["id"], version)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	data, warnings, err := exporter.Export(j)
+	if err != nil {
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	for _, warning := range warnings {
+		w.Header().Add("X-Export-Warning", warning)
+	}
+	w.Header().Set("Content-Type", exporter.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="journey-%s-v%d%s"`, j.ID, j.Version, exporter.Extension()))
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+// HandleGenerateStep regenerates one email of a journey, leaving the others
+// untouched: POST /api/generate-step
 func HandleGenerateStep(w http.ResponseWriter, r *http.Request) {
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
 	}
-	http.Error(w, "Not implemented", http.StatusNotImplemented)
+
+	if globalOrchestrator == nil {
+		http.Error(w, "Orchestrator not initialized", http.StatusInternalServerError)
+		return
+	}
+
+	var req models.GenerateStepRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"error": "invalid request body",
+		})
+		return
+	}
+
+	resp, err := globalOrchestrator.RegenerateEmail(r.Context(), &req)
+	if err != nil {
+		writeJourneyError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, resp)
 }
 
+// HandleSimulateJourney reports which emails a journey would send to a
+// subscriber who behaves as scripted, and where its branches take over:
+// POST /api/simulate-journey
+// The simulation makes no model calls.
+func HandleSimulateJourney(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	var req models.SimulateJourneyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"error": "invalid request body",
+		})
+		return
+	}
+
+	result, err := simulator.Run(req.Journey, req.Events)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+// HandleTestSend delivers every email of a stored journey, in order, to
+// the SMTP server in SMTP_ADDR (MailHog on localhost:1025 by default):
+// POST /api/journeys/{id}/test-send. The latest version is sent unless
+// ?version=N is given. Delays are skipped unless the body sets a
+// timeCompression factor, and the response lists per-email results. The
+// body's "to" must be EMAIL_TO or listed in TEST_SEND_RECIPIENTS.
+func HandleTestSend(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if globalJourneyStore == nil {
+		http.Error(w, "Journey store not initialized", http.StatusInternalServerError)
+		return
+	}
+
+	var req models.TestSendRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"error": "invalid request body",
+		})
+		return
+	}
+	if req.TimeCompression < 0 {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"error": "timeCompression must not be negative",
+		})
+		return
+	}
+	version, ok := versionParam(w, r)
+	if !ok {
+		return
+	}
+
+	j, err := globalJourneyStore.Load(mux.Vars(r)["id"], version)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	cfg := testsend.ConfigFromEnv()
+	if req.To != "" {
+		if cfg, err = cfg.WithRecipient(req.To); err != nil {
+			status := http.StatusBadRequest
+			if errors.Is(err, testsend.ErrRecipientNotAllowed) {
+				status = http.StatusForbidden
+			}
+			writeJSON(w, status, map[string]interface{}{
+				"error": err.Error(),
+			})
+			return
+		}
+	}
+	results, err := testsend.Send(r.Context(), j, cfg, req.TimeCompression)
+	switch {
+	case errors.Is(err, testsend.ErrTooSlow):
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	case err != nil && results == nil:
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+	// Otherwise the request was cancelled midway; report what was sent
+
+	resp := models.TestSendResponse{ID: j.ID, Version: j.Version, SMTPAddr: cfg.SMTPAddr, Results: results}
+	if resp.SMTPAddr == "" {
+		resp.SMTPAddr = testsend.DefaultSMTPAddr
+	}
+	for _, result := range results {
+		if result.OK {
+			resp.Sent++
+		} else {
+			resp.Failed++
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// versionParam reads ?version=N, 0 when absent. On a bad value it writes
+// a 400 response and returns false.
+func versionParam(w http.ResponseWriter, r *http.Request) (int, bool) {
+	v := r.URL.Query().Get("version")
+	if v == "" {
+		return 0, true
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"error": "version must be a positive integer",
+		})
+		return 0, false
+	}
+	return n, true
+}
+
+// writeJourneyError maps orchestrator journey errors to HTTP statuses.
+func writeJourneyError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, orchestrator.ErrInvalidBrief), errors.Is(err, orchestrator.ErrInvalidJourney):
+		status = http.StatusBadRequest
+	case errors.Is(err, orchestrator.ErrNoJourney):
+		status = http.StatusBadGateway
+	}
+	writeJSON(w, status, map[string]interface{}{
+		"error": err.Error(),
+	})
+}
+
+// writeStoreError maps journey store errors to HTTP statuses.
+func writeStoreError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, storage.ErrNotFound):
+		writeJSON(w, http.StatusNotFound, map[string]interface{}{"error": err.Error()})
+		return
+	case errors.Is(err, storage.ErrInvalidWorkspace):
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	case errors.Is(err, storage.ErrWorkspaceMismatch):
+		writeJSON(w, http.StatusConflict, map[string]interface{}{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusInternalServerError, map[string]interface{}{
+		"error":   "failed to access journey store",
+		"details": err.Error(),
+	})
+}
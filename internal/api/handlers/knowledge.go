@@ -0,0 +1,299 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"JourneyBuilder/internal/knowledge"
+
+	"github.com/gorilla/mux"
+)
+
+// KnowledgeHandler exposes the knowledge base over HTTP.
+type KnowledgeHandler struct {
+	kb         *knowledge.KnowledgeBase
+	adminToken string // writes require "Authorization: Bearer <token>"; unset disables them
+}
+
+func NewKnowledgeHandler(kb *knowledge.KnowledgeBase) *KnowledgeHandler {
+	return &KnowledgeHandler{kb: kb, adminToken: os.Getenv("KB_ADMIN_TOKEN")}
+}
+
+// Status reports the loaded knowledge version and the last reload error: GET /api/v1/knowledge/status
+// A failed reload leaves the previous version serving, so lastError being set
+// means the files on disk are not what is being served.
+func (h *KnowledgeHandler) Status(w http.ResponseWriter, r *http.Request) {
+	status := h.kb.Status()
+	state := "ok"
+	if status.LastError != "" {
+		state = "stale"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":    state,
+		"knowledge": status,
+	})
+}
+
+// ListFrameworks: GET /api/v1/frameworks and GET /api/v1/knowledge/frameworks
+func (h *KnowledgeHandler) ListFrameworks(w http.ResponseWriter, r *http.Request) {
+	listEntries(w, "frameworks", h.kb.Frameworks())
+}
+
+// GetFramework: GET /api/v1/knowledge/frameworks/{key}
+func (h *KnowledgeHandler) GetFramework(w http.ResponseWriter, r *http.Request) {
+	getEntry(w, r, "framework", h.kb.Frameworks())
+}
+
+// CreateFramework: POST /api/v1/knowledge/frameworks with {"key": ..., <framework fields>}
+func (h *KnowledgeHandler) CreateFramework(w http.ResponseWriter, r *http.Request) {
+	writeEntry(h, w, r, "framework", true, h.kb.CreateFramework)
+}
+
+// UpdateFramework: PUT /api/v1/knowledge/frameworks/{key}
+func (h *KnowledgeHandler) UpdateFramework(w http.ResponseWriter, r *http.Request) {
+	writeEntry(h, w, r, "framework", false, h.kb.UpdateFramework)
+}
+
+// DeleteFramework: DELETE /api/v1/knowledge/frameworks/{key}
+func (h *KnowledgeHandler) DeleteFramework(w http.ResponseWriter, r *http.Request) {
+	deleteEntry(h, w, r, h.kb.DeleteFramework)
+}
+
+// ListSequences: GET /api/v1/knowledge/sequences, optionally filtered with ?vertical=
+func (h *KnowledgeHandler) ListSequences(w http.ResponseWriter, r *http.Request) {
+	vertical := r.URL.Query().Get("vertical")
+	if vertical == "" {
+		listEntries(w, "sequences", h.kb.SequenceTemplates())
+		return
+	}
+	listEntries(w, "sequences", h.kb.ListSequencesForVertical(vertical))
+}
+
+// ListSequencesForVertical: GET /api/v1/sequences/{vertical}
+// The sequences written for a vertical, plus the general ones.
+func (h *KnowledgeHandler) ListSequencesForVertical(w http.ResponseWriter, r *http.Request) {
+	vertical := mux.Vars(r)["vertical"]
+	sequences := h.kb.ListSequencesForVertical(vertical)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"vertical":  vertical,
+		"sequences": sequences,
+		"count":     len(sequences),
+	})
+}
+
+// GetSequence: GET /api/v1/knowledge/sequences/{key}
+func (h *KnowledgeHandler) GetSequence(w http.ResponseWriter, r *http.Request) {
+	getEntry(w, r, "sequence", h.kb.SequenceTemplates())
+}
+
+// CreateSequence: POST /api/v1/knowledge/sequences with {"key": ..., <template fields>}
+func (h *KnowledgeHandler) CreateSequence(w http.ResponseWriter, r *http.Request) {
+	writeEntry(h, w, r, "sequence", true, h.kb.CreateSequenceTemplate)
+}
+
+// UpdateSequence: PUT /api/v1/knowledge/sequences/{key}
+func (h *KnowledgeHandler) UpdateSequence(w http.ResponseWriter, r *http.Request) {
+	writeEntry(h, w, r, "sequence", false, h.kb.UpdateSequenceTemplate)
+}
+
+// DeleteSequence: DELETE /api/v1/knowledge/sequences/{key}
+func (h *KnowledgeHandler) DeleteSequence(w http.ResponseWriter, r *http.Request) {
+	deleteEntry(h, w, r, h.kb.DeleteSequenceTemplate)
+}
+
+// ListVerticals: GET /api/v1/knowledge/verticals
+func (h *KnowledgeHandler) ListVerticals(w http.ResponseWriter, r *http.Request) {
+	listEntries(w, "verticals", h.kb.VerticalGuides())
+}
+
+// GetVertical: GET /api/v1/knowledge/verticals/{key}
+func (h *KnowledgeHandler) GetVertical(w http.ResponseWriter, r *http.Request) {
+	getEntry(w, r, "vertical", h.kb.VerticalGuides())
+}
+
+// CreateVertical: POST /api/v1/knowledge/verticals with {"key": ..., <guidance fields>}
+func (h *KnowledgeHandler) CreateVertical(w http.ResponseWriter, r *http.Request) {
+	writeEntry(h, w, r, "vertical", true, h.kb.CreateVerticalGuidance)
+}
+
+// UpdateVertical: PUT /api/v1/knowledge/verticals/{key}
+func (h *KnowledgeHandler) UpdateVertical(w http.ResponseWriter, r *http.Request) {
+	writeEntry(h, w, r, "vertical", false, h.kb.UpdateVerticalGuidance)
+}
+
+// DeleteVertical: DELETE /api/v1/knowledge/verticals/{key}
+func (h *KnowledgeHandler) DeleteVertical(w http.ResponseWriter, r *http.Request) {
+	deleteEntry(h, w, r, h.kb.DeleteVerticalGuidance)
+}
+
+// authorized checks the admin token for write requests. Without a configured
+// KB_ADMIN_TOKEN every write is refused.
+func (h *KnowledgeHandler) authorized(w http.ResponseWriter, r *http.Request) bool {
+	if h.adminToken == "" {
+		writeJSON(w, http.StatusForbidden, map[string]interface{}{
+			"error": "knowledge base writes are disabled; set KB_ADMIN_TOKEN to enable them",
+		})
+		return false
+	}
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(h.adminToken)) == 1 {
+		return true
+	}
+	writeJSON(w, http.StatusUnauthorized, map[string]interface{}{
+		"error": "admin token required",
+	})
+	return false
+}
+
+func listEntries[T any](w http.ResponseWriter, name string, entries map[string]T) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		name:    entries,
+		"count": len(entries),
+	})
+}
+
+func getEntry[T any](w http.ResponseWriter, r *http.Request, name string, entries map[string]T) {
+	key := mux.Vars(r)["key"]
+	entry, ok := entries[key]
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]interface{}{
+			"error": fmt.Sprintf("%s %q not found", name, key),
+		})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"key": key,
+		name:  entry,
+	})
+}
+
+// writeEntry decodes a create or update body and applies it with save.
+// Create takes the key from the body's "key" field, update from the URL.
+func writeEntry[T any](h *KnowledgeHandler, w http.ResponseWriter, r *http.Request, name string, create bool, save func(string, T) error) {
+	if !h.authorized(w, r) {
+		return
+	}
+
+	raw, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"error": "invalid request body",
+		})
+		return
+	}
+
+	key := mux.Vars(r)["key"]
+	if create {
+		key, raw, err = splitKey(raw)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]interface{}{
+				"error":   "invalid request body",
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+
+	// Decode with the loader's rules so the API accepts exactly what the files do
+	var entry T
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&entry); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"error":   "invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := save(key, entry); err != nil {
+		writeKnowledgeError(w, err)
+		return
+	}
+
+	status := http.StatusOK
+	if create {
+		status = http.StatusCreated
+	}
+	writeJSON(w, status, map[string]interface{}{
+		"key": key,
+		name:  entry,
+	})
+}
+
+func deleteEntry(h *KnowledgeHandler, w http.ResponseWriter, r *http.Request, remove func(string) error) {
+	if !h.authorized(w, r) {
+		return
+	}
+	if err := remove(mux.Vars(r)["key"]); err != nil {
+		writeKnowledgeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// splitKey removes the "key" field from a create body and returns it
+// together with the remaining entry fields.
+func splitKey(raw []byte) (string, []byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return "", nil, err
+	}
+	var key string
+	if err := json.Unmarshal(fields["key"], &key); err != nil || key == "" {
+		return "", nil, errors.New(`"key" is required`)
+	}
+	delete(fields, "key")
+	rest, err := json.Marshal(fields)
+	return key, rest, err
+}
+
+// writeKnowledgeError maps knowledge base write errors to HTTP statuses.
+func writeKnowledgeError(w http.ResponseWriter, err error) {
+	var validationErr *knowledge.ValidationError
+	var inUseErr *knowledge.InUseError
+	switch {
+	case errors.As(err, &validationErr):
+		details := make([]string, len(validationErr.Errors))
+		for i, e := range validationErr.Errors {
+			details[i] = e.Error()
+		}
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+			"error":   "validation failed",
+			"details": details,
+		})
+	case errors.As(err, &inUseErr):
+		writeJSON(w, http.StatusConflict, map[string]interface{}{
+			"error":     err.Error(),
+			"sequences": inUseErr.Sequences,
+			"verticals": inUseErr.Verticals,
+		})
+	case errors.Is(err, knowledge.ErrNotFound):
+		writeJSON(w, http.StatusNotFound, map[string]interface{}{"error": err.Error()})
+	case errors.Is(err, knowledge.ErrExists):
+		writeJSON(w, http.StatusConflict, map[string]interface{}{"error": err.Error()})
+	case errors.Is(err, knowledge.ErrInvalidKey):
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+	default:
+		writeJSON(w, http.StatusInternalServerError, map[string]interface{}{
+			"error":   "failed to save knowledge base",
+			"details": err.Error(),
+		})
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
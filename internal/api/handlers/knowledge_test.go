@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"JourneyBuilder/internal/knowledge"
+
+	"github.com/gorilla/mux"
+)
+
+// newKnowledgeRouter serves the knowledge write routes over a copy of the
+// shipped knowledge files, with KB_ADMIN_TOKEN set to token.
+func newKnowledgeRouter(t *testing.T, token string) (*mux.Router, *knowledge.KnowledgeBase) {
+	t.Helper()
+	dir := t.TempDir()
+	for _, name := range []string{"frameworks.json", "sequence.json", "verticals.json"} {
+		data, err := os.ReadFile(filepath.Join("..", "..", "..", "data", "knowledge", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	paths := knowledge.DefaultPaths(dir)
+	kb, err := knowledge.NewKnowledgeBase(paths.Frameworks, paths.Sequences, paths.Verticals)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("KB_ADMIN_TOKEN", token)
+	h := NewKnowledgeHandler(kb)
+	router := mux.NewRouter()
+	router.HandleFunc("/knowledge/frameworks", h.CreateFramework).Methods("POST")
+	router.HandleFunc("/knowledge/frameworks/{key}", h.UpdateFramework).Methods("PUT")
+	router.HandleFunc("/knowledge/frameworks/{key}", h.DeleteFramework).Methods("DELETE")
+	return router, kb
+}
+
+const testFramework = `{"key": "slap", "name": "SLAP", "acronym": "Stop, Look, Act, Purchase",
+	"components": ["Stop", "Look", "Act", "Purchase"], "best_for": ["BOFU"], "funnel_stage": "BOFU"}`
+
+func serve(router *mux.Router, method, path, body, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestKnowledgeWritesWithoutToken(t *testing.T) {
+	router, kb := newKnowledgeRouter(t, "")
+	tests := []struct {
+		method, path, body string
+	}{
+		{"POST", "/knowledge/frameworks", testFramework},
+		{"PUT", "/knowledge/frameworks/aida", `{"name": "AIDA"}`},
+		{"DELETE", "/knowledge/frameworks/aida", ""},
+	}
+	for _, tt := range tests {
+		// Any bearer token is refused while none is configured
+		for _, token := range []string{"", "guess"} {
+			if rec := serve(router, tt.method, tt.path, tt.body, token); rec.Code != http.StatusForbidden {
+				t.Errorf("%s %s with token %q: status %d, want 403", tt.method, tt.path, token, rec.Code)
+			}
+		}
+	}
+	frameworks := kb.Frameworks()
+	if _, ok := frameworks["slap"]; ok {
+		t.Error("framework was created without a token")
+	}
+	if aida, ok := frameworks["aida"]; !ok || aida.Acronym == "" {
+		t.Errorf("aida was changed without a token: %+v", aida)
+	}
+}
+
+func TestKnowledgeWritesWithToken(t *testing.T) {
+	router, kb := newKnowledgeRouter(t, "s3cret")
+	if rec := serve(router, "POST", "/knowledge/frameworks", testFramework, ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("POST without a token: status %d, want 401", rec.Code)
+	}
+	if rec := serve(router, "POST", "/knowledge/frameworks", testFramework, "wrong"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("POST with the wrong token: status %d, want 401", rec.Code)
+	}
+	if rec := serve(router, "POST", "/knowledge/frameworks", testFramework, "s3cret"); rec.Code != http.StatusCreated {
+		t.Fatalf("POST with the token: status %d: %s", rec.Code, rec.Body)
+	}
+	if _, ok := kb.Frameworks()["slap"]; !ok {
+		t.Error("framework was not created")
+	}
+	if rec := serve(router, "DELETE", "/knowledge/frameworks/slap", "", "s3cret"); rec.Code != http.StatusNoContent {
+		t.Errorf("DELETE with the token: status %d: %s", rec.Code, rec.Body)
+	}
+}
@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"JourneyBuilder/internal/models"
+)
+
+const (
+	// sseRetryMillis is the reconnection delay suggested to clients via "retry:".
+	sseRetryMillis = 3000
+	// sseStreamTTL is how long a finished stream stays available for resumption.
+	sseStreamTTL = 5 * time.Minute
+	// sseEvictInterval is how often expired streams are dropped.
+	sseEvictInterval = time.Minute
+)
+
+// sseEvent is an encoded event kept for replay.
+type sseEvent struct {
+	seq   int
+	event models.StreamEventType
+	data  []byte
+}
+
+// sseStream buffers every event of one generation so a dropped client can
+// resume with Last-Event-ID. Event IDs have the form "<streamID>:<seq>".
+type sseStream struct {
+	id         string
+	mu         sync.Mutex
+	events     []sseEvent
+	done       bool
+	finishedAt time.Time
+	notify     chan struct{} // closed and replaced whenever an event is appended
+}
+
+func (s *sseStream) append(ev models.StreamEvent) {
+	data, err := json.Marshal(ev.Data)
+	if err != nil {
+		ev = models.StreamEvent{Type: models.StreamEventError, Data: models.ErrorPayload{Message: err.Error()}}
+		data, _ = json.Marshal(ev.Data)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, sseEvent{seq: len(s.events) + 1, event: ev.Type, data: data})
+	if ev.Type == models.StreamEventDone || ev.Type == models.StreamEventError {
+		s.done = true
+		s.finishedAt = time.Now()
+	}
+	close(s.notify)
+	s.notify = make(chan struct{})
+}
+
+// finish marks the stream complete even if no terminal event was produced.
+func (s *sseStream) finish() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.done {
+		return
+	}
+	s.done = true
+	s.finishedAt = time.Now()
+	close(s.notify)
+	s.notify = make(chan struct{})
+}
+
+// since returns events after seq, whether the stream is complete, and a
+// channel that is closed when more events arrive.
+func (s *sseStream) since(seq int) ([]sseEvent, bool, <-chan struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if seq < 0 {
+		seq = 0
+	}
+	if seq > len(s.events) {
+		seq = len(s.events)
+	}
+	pending := append([]sseEvent(nil), s.events[seq:]...)
+	return pending, s.done, s.notify
+}
+
+// sseHub tracks in-flight and recently finished streams.
+type sseHub struct {
+	mu      sync.Mutex
+	streams map[string]*sseStream
+}
+
+// newSSEHub returns an empty hub that evicts expired streams every
+// sseEvictInterval for the life of the process.
+func newSSEHub() *sseHub {
+	h := &sseHub{streams: make(map[string]*sseStream)}
+	go func() {
+		for range time.Tick(sseEvictInterval) {
+			h.evictExpired(time.Now())
+		}
+	}()
+	return h
+}
+
+// create registers a new stream and evicts expired ones.
+func (h *sseHub) create() *sseStream {
+	stream := &sseStream{id: newStreamID(), notify: make(chan struct{})}
+	h.evictExpired(time.Now())
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.streams[stream.id] = stream
+	return stream
+}
+
+// evictExpired drops streams that finished more than sseStreamTTL before now.
+func (h *sseHub) evictExpired(now time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for id, s := range h.streams {
+		s.mu.Lock()
+		expired := s.done && now.Sub(s.finishedAt) > sseStreamTTL
+		s.mu.Unlock()
+		if expired {
+			delete(h.streams, id)
+		}
+	}
+}
+
+func (h *sseHub) get(id string) *sseStream {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.streams[id]
+}
+
+// serve writes events after seq to w, following the stream until it
+// completes or the client goes away.
+func (h *sseHub) serve(w http.ResponseWriter, r *http.Request, stream *sseStream, seq int) {
+	fmt.Fprintf(w, "retry: %d\n\n", sseRetryMillis)
+	flush(w)
+
+	for {
+		events, done, more := stream.since(seq)
+		for _, ev := range events {
+			writeSSE(w, fmt.Sprintf("%s:%d", stream.id, ev.seq), ev.event, ev.data)
+			seq = ev.seq
+		}
+		if done {
+			return
+		}
+		select {
+		case <-more:
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// writeSSE writes one SSE event with an id and a single-line JSON payload, and flushes it.
+func writeSSE(w http.ResponseWriter, id string, event models.StreamEventType, data []byte) {
+	var sb strings.Builder
+	if id != "" {
+		sb.WriteString("id: " + id + "\n")
+	}
+	sb.WriteString("event: " + string(event) + "\n")
+	sb.WriteString("data: ")
+	sb.Write(data)
+	sb.WriteString("\n\n")
+	_, _ = w.Write([]byte(sb.String()))
+	flush(w)
+}
+
+// writeSSEError writes a one-off error event that is not part of any stream.
+func writeSSEError(w http.ResponseWriter, message string) {
+	data, _ := json.Marshal(models.ErrorPayload{Message: message})
+	writeSSE(w, "", models.StreamEventError, data)
+}
+
+// parseLastEventID splits "<streamID>:<seq>" into its parts.
+func parseLastEventID(value string) (string, int, bool) {
+	id, seqStr, ok := strings.Cut(strings.TrimSpace(value), ":")
+	if !ok || id == "" {
+		return "", 0, false
+	}
+	seq, err := strconv.Atoi(seqStr)
+	if err != nil || seq < 0 {
+		return "", 0, false
+	}
+	return id, seq, true
+}
+
+func newStreamID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(b)
+}
+
+func flush(w http.ResponseWriter) {
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
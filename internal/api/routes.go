@@ -2,18 +2,18 @@ package api
 
 import (
 	"encoding/json"
-	"fmt"
 	"net/http"
 	"time"
 
 	"JourneyBuilder/internal/api/handlers"
+	"JourneyBuilder/internal/knowledge"
 	"JourneyBuilder/internal/orchestrator"
 
 	"github.com/gorilla/mux"
 )
 
 // SetupRoutes configures all API routes.
-func SetupRoutes(router *mux.Router, orch *orchestrator.Orchestrator) {
+func SetupRoutes(router *mux.Router, orch *orchestrator.Orchestrator, kb *knowledge.KnowledgeBase) {
 	// API group
 	api := router.PathPrefix("/api/v1").Subrouter()
 
@@ -21,14 +21,39 @@ func SetupRoutes(router *mux.Router, orch *orchestrator.Orchestrator) {
 	chatHandler := handlers.NewChatHandler(orch)
 	api.HandleFunc("/chat", chatHandler.Chat).Methods("POST", "OPTIONS")
 	api.HandleFunc("/chat/stream", chatHandler.ChatStream).Methods("POST", "OPTIONS")
+	api.HandleFunc("/chat/stream/{id}", chatHandler.ResumeStream).Methods("GET")
 
 	// Health check
 	api.HandleFunc("/health", healthCheck).Methods("GET")
 	api.HandleFunc("/status", statusCheck).Methods("GET")
 
 	// Knowledge base endpoints (admin/debug)
-	api.HandleFunc("/frameworks", listFrameworks).Methods("GET")
-	api.HandleFunc("/sequences/{vertical}", listSequences).Methods("GET")
+	knowledgeHandler := handlers.NewKnowledgeHandler(kb)
+	api.HandleFunc("/knowledge/status", knowledgeHandler.Status).Methods("GET")
+
+	// Read-only catalogue
+	api.HandleFunc("/frameworks", knowledgeHandler.ListFrameworks).Methods("GET")
+	api.HandleFunc("/sequences/{vertical}", knowledgeHandler.ListSequencesForVertical).Methods("GET")
+
+	// Knowledge base CRUD, kept apart from the catalogue paths above
+	admin := api.PathPrefix("/knowledge").Subrouter()
+	admin.HandleFunc("/frameworks", knowledgeHandler.ListFrameworks).Methods("GET")
+	admin.HandleFunc("/frameworks", knowledgeHandler.CreateFramework).Methods("POST")
+	admin.HandleFunc("/frameworks/{key}", knowledgeHandler.GetFramework).Methods("GET")
+	admin.HandleFunc("/frameworks/{key}", knowledgeHandler.UpdateFramework).Methods("PUT")
+	admin.HandleFunc("/frameworks/{key}", knowledgeHandler.DeleteFramework).Methods("DELETE")
+
+	admin.HandleFunc("/sequences", knowledgeHandler.ListSequences).Methods("GET")
+	admin.HandleFunc("/sequences", knowledgeHandler.CreateSequence).Methods("POST")
+	admin.HandleFunc("/sequences/{key}", knowledgeHandler.GetSequence).Methods("GET")
+	admin.HandleFunc("/sequences/{key}", knowledgeHandler.UpdateSequence).Methods("PUT")
+	admin.HandleFunc("/sequences/{key}", knowledgeHandler.DeleteSequence).Methods("DELETE")
+
+	admin.HandleFunc("/verticals", knowledgeHandler.ListVerticals).Methods("GET")
+	admin.HandleFunc("/verticals", knowledgeHandler.CreateVertical).Methods("POST")
+	admin.HandleFunc("/verticals/{key}", knowledgeHandler.GetVertical).Methods("GET")
+	admin.HandleFunc("/verticals/{key}", knowledgeHandler.UpdateVertical).Methods("PUT")
+	admin.HandleFunc("/verticals/{key}", knowledgeHandler.DeleteVertical).Methods("DELETE")
 }
 
 // Health check endpoint.
@@ -57,40 +82,3 @@ func statusCheck(w http.ResponseWriter, r *http.Request) {
 		"timestamp":  time.Now().UTC().Format(time.RFC3339),
 	})
 }
-
-// Lists available copywriting frameworks (AIDA, PAS, FAB, BAB, 4Ps, Hero)
-func listFrameworks(w http.ResponseWriter, r *http.Request) {
-	frameworks := []string{
-		"AIDA", "PAS", "FAB", "BAB", "4Ps", "Hero",
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"frameworks":  frameworks,
-		"count":       len(frameworks),
-		"description": "Available copywriting frameworks for email sequences",
-	})
-}
-
-// Lists sequences for specific vertical (supplements, coaching, etc.)
-func listSequences(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	vertical := vars["vertical"]
-
-	sequences := map[string]string{
-		"first_purchase":   "5 emails over 7-14 days",
-		"cart_recovery":    "3 emails in 48 hours",
-		"onboarding":       "5 emails over 21 days",
-		"churn_prevention": "4 emails over 30 days",
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"vertical":  vertical,
-		"sequences": sequences,
-		"count":     len(sequences),
-		"message":   fmt.Sprintf("Available sequences for %s vertical", vertical),
-	})
-}
@@ -0,0 +1,153 @@
+// Package branching models the rules that move a contact through, out of or
+// between sequences, such as
+//
+//	IF purchased THEN exit; IF no_engagement_for 7 days THEN start re_engagement
+//
+// Sequence templates write them in their branching_logic field; generated
+// journeys carry the parsed rules so they can be exported and simulated.
+package branching
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Event is something a contact does, or fails to do.
+type Event string
+
+const (
+	EventOpened       Event = "opened"
+	EventClicked      Event = "clicked"
+	EventPurchased    Event = "purchased"
+	EventNoEngagement Event = "no_engagement_for" // no open or click for Condition.Hours
+)
+
+// ActionType is what happens when a rule's condition is met.
+type ActionType string
+
+const (
+	ActionExit  ActionType = "exit"  // leave the journey
+	ActionJump  ActionType = "jump"  // continue from Action.Email
+	ActionStart ActionType = "start" // leave and enter Action.Sequence
+	ActionWait  ActionType = "wait"  // hold the next email for Action.Hours
+)
+
+// Condition is the IF half of a rule.
+type Condition struct {
+	Event Event `json:"event"`
+	Email int   `json:"email,omitempty"` // only events on this email count; 0 for any email
+	// Hours is the quiet period for no_engagement_for. For other events it
+	// limits the match to that many hours after the email was sent; 0 means any time.
+	Hours int `json:"hours,omitempty"`
+}
+
+// Action is the THEN half of a rule.
+type Action struct {
+	Type     ActionType `json:"type"`
+	Email    int        `json:"email,omitempty"`    // jump target
+	Sequence string     `json:"sequence,omitempty"` // start target, a sequence template key
+	Hours    int        `json:"hours,omitempty"`    // wait length
+}
+
+// Rule is one IF ... THEN ... statement. Rules are checked in order and the
+// first one whose condition is met applies.
+type Rule struct {
+	When Condition `json:"when"`
+	Then Action    `json:"then"`
+}
+
+// Validate checks that the rule is complete and uses known events and actions.
+func (r Rule) Validate() error {
+	switch r.When.Event {
+	case EventOpened, EventClicked, EventPurchased:
+	case EventNoEngagement:
+		if r.When.Hours <= 0 {
+			return errors.New("no_engagement_for needs a duration")
+		}
+	case "":
+		return errors.New("missing event")
+	default:
+		return fmt.Errorf("unknown event %q", r.When.Event)
+	}
+	if r.When.Email < 0 || r.When.Hours < 0 {
+		return errors.New("condition email and hours must not be negative")
+	}
+
+	switch r.Then.Type {
+	case ActionExit:
+	case ActionJump:
+		if r.Then.Email <= 0 {
+			return errors.New("jump needs an email number")
+		}
+	case ActionStart:
+		if !sequenceName.MatchString(r.Then.Sequence) {
+			return fmt.Errorf("start needs a sequence key, got %q", r.Then.Sequence)
+		}
+	case ActionWait:
+		if r.Then.Hours <= 0 {
+			return errors.New("wait needs a duration")
+		}
+	case "":
+		return errors.New("missing action")
+	default:
+		return fmt.Errorf("unknown action %q", r.Then.Type)
+	}
+	return nil
+}
+
+// String renders the rule in the form Parse accepts.
+func (r Rule) String() string {
+	var sb strings.Builder
+	sb.WriteString("IF ")
+	sb.WriteString(string(r.When.Event))
+	if r.When.Event == EventNoEngagement {
+		sb.WriteString(" " + formatHours(r.When.Hours))
+	}
+	if r.When.Email > 0 {
+		sb.WriteString(fmt.Sprintf(" email %d", r.When.Email))
+	}
+	if r.When.Event != EventNoEngagement && r.When.Hours > 0 {
+		sb.WriteString(" within " + formatHours(r.When.Hours))
+	}
+
+	sb.WriteString(" THEN ")
+	sb.WriteString(string(r.Then.Type))
+	switch r.Then.Type {
+	case ActionJump:
+		sb.WriteString(fmt.Sprintf(" to email %d", r.Then.Email))
+	case ActionStart:
+		sb.WriteString(" " + r.Then.Sequence)
+	case ActionWait:
+		sb.WriteString(" " + formatHours(r.Then.Hours))
+	}
+	return sb.String()
+}
+
+// Format renders rules in the form Parse accepts, separated by "; ".
+func Format(rules []Rule) string {
+	parts := make([]string, len(rules))
+	for i, r := range rules {
+		parts[i] = r.String()
+	}
+	return strings.Join(parts, "; ")
+}
+
+// formatHours renders hours in whole days or weeks where possible.
+func formatHours(h int) string {
+	switch {
+	case h%(24*7) == 0:
+		return plural(h/(24*7), "week")
+	case h%24 == 0:
+		return plural(h/24, "day")
+	default:
+		return plural(h, "hour")
+	}
+}
+
+func plural(n int, unit string) string {
+	if n == 1 {
+		return "1 " + unit
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
@@ -0,0 +1,200 @@
+package branching
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// sequenceName is a sequence template key, as used by start.
+var sequenceName = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// eventWords maps the accepted spellings of each event.
+var eventWords = map[string]Event{
+	"opened": EventOpened, "opens": EventOpened,
+	"clicked": EventClicked, "clicks": EventClicked,
+	"purchased": EventPurchased, "purchases": EventPurchased,
+	"no_engagement_for": EventNoEngagement,
+}
+
+// unitHours maps duration units to hours.
+var unitHours = map[string]int{
+	"hour": 1, "hours": 1,
+	"day": 24, "days": 24,
+	"week": 24 * 7, "weeks": 24 * 7,
+}
+
+// SyntaxError reports a rule that does not follow the grammar.
+type SyntaxError struct {
+	Rule    int    // 1-based position of the rule in the input
+	Text    string // the rule as written
+	Message string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("rule %d %q: %s", e.Rule, e.Text, e.Message)
+}
+
+// Parse reads rules separated by ";" or ",". Each rule has the form
+//
+//	IF <event> [email <n>] [within <n> <unit>] THEN <action>
+//
+// where event is opened, clicked, purchased or no_engagement_for <n> <unit>,
+// and action is exit, jump [to] email <n>, start <sequence_key> or
+// wait <n> <unit>. Units are hours, days or weeks; keywords are not case
+// sensitive. An empty string has no rules.
+func Parse(s string) ([]Rule, error) {
+	var rules []Rule
+	for i, text := range strings.FieldsFunc(s, func(r rune) bool { return r == ';' || r == ',' }) {
+		text = strings.TrimSpace(text)
+		if text == "" {
+			continue
+		}
+		rule, err := parseRule(strings.Fields(text))
+		if err == nil {
+			err = rule.Validate()
+		}
+		if err != nil {
+			return nil, &SyntaxError{Rule: i + 1, Text: text, Message: err.Error()}
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// words is a cursor over the words of one rule.
+type words struct {
+	list []string
+	pos  int
+}
+
+func (w *words) peek() string {
+	if w.pos >= len(w.list) {
+		return ""
+	}
+	return strings.ToLower(w.list[w.pos])
+}
+
+func (w *words) next() string {
+	word := w.peek()
+	if word != "" {
+		w.pos++
+	}
+	return word
+}
+
+func (w *words) expect(keyword string) error {
+	if got := w.next(); got != keyword {
+		return fmt.Errorf("expected %q, got %q", keyword, got)
+	}
+	return nil
+}
+
+// number reads a positive integer.
+func (w *words) number(what string) (int, error) {
+	word := w.next()
+	n, err := strconv.Atoi(word)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("expected %s, got %q", what, word)
+	}
+	return n, nil
+}
+
+// hours reads "<n> <unit>".
+func (w *words) hours() (int, error) {
+	n, err := w.number("a duration such as \"3 days\"")
+	if err != nil {
+		return 0, err
+	}
+	unit := w.next()
+	size, ok := unitHours[unit]
+	if !ok {
+		return 0, fmt.Errorf("expected hours, days or weeks, got %q", unit)
+	}
+	return n * size, nil
+}
+
+func parseRule(list []string) (Rule, error) {
+	w := &words{list: list}
+	var rule Rule
+
+	if err := w.expect("if"); err != nil {
+		return rule, err
+	}
+	word := w.next()
+	event, ok := eventWords[word]
+	if !ok {
+		return rule, fmt.Errorf("unknown event %q (want opened, clicked, purchased or no_engagement_for)", word)
+	}
+	rule.When.Event = event
+	if event == EventNoEngagement {
+		h, err := w.hours()
+		if err != nil {
+			return rule, err
+		}
+		rule.When.Hours = h
+	}
+
+	for w.peek() != "then" {
+		switch word := w.next(); word {
+		case "email":
+			n, err := w.number("an email number")
+			if err != nil {
+				return rule, err
+			}
+			rule.When.Email = n
+		case "within":
+			if event == EventNoEngagement {
+				return rule, fmt.Errorf("within cannot be used with %s", event)
+			}
+			h, err := w.hours()
+			if err != nil {
+				return rule, err
+			}
+			rule.When.Hours = h
+		case "":
+			return rule, fmt.Errorf("missing THEN")
+		default:
+			return rule, fmt.Errorf("unexpected %q in condition", word)
+		}
+	}
+	w.next() // then
+
+	switch word := w.next(); word {
+	case "exit":
+		rule.Then.Type = ActionExit
+	case "jump":
+		rule.Then.Type = ActionJump
+		if w.peek() == "to" {
+			w.next()
+		}
+		if err := w.expect("email"); err != nil {
+			return rule, err
+		}
+		n, err := w.number("an email number")
+		if err != nil {
+			return rule, err
+		}
+		rule.Then.Email = n
+	case "start":
+		rule.Then.Type = ActionStart
+		rule.Then.Sequence = w.next()
+	case "wait":
+		rule.Then.Type = ActionWait
+		h, err := w.hours()
+		if err != nil {
+			return rule, err
+		}
+		rule.Then.Hours = h
+	case "":
+		return rule, fmt.Errorf("missing action after THEN")
+	default:
+		return rule, fmt.Errorf("unknown action %q (want exit, jump, start or wait)", word)
+	}
+
+	if rest := w.next(); rest != "" {
+		return rule, fmt.Errorf("unexpected %q after action", rest)
+	}
+	return rule, nil
+}
@@ -0,0 +1,122 @@
+package branching
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []Rule
+	}{
+		{"", nil},
+		{" ; ", nil},
+		{"IF opened THEN exit", []Rule{{When: Condition{Event: EventOpened}, Then: Action{Type: ActionExit}}}},
+		{"if opens then exit", []Rule{{When: Condition{Event: EventOpened}, Then: Action{Type: ActionExit}}}},
+		{"IF clicks THEN exit", []Rule{{When: Condition{Event: EventClicked}, Then: Action{Type: ActionExit}}}},
+		{"IF purchases THEN exit", []Rule{{When: Condition{Event: EventPurchased}, Then: Action{Type: ActionExit}}}},
+		{"IF no_engagement_for 36 hours THEN exit", []Rule{{When: Condition{Event: EventNoEngagement, Hours: 36}, Then: Action{Type: ActionExit}}}},
+		{"IF no_engagement_for 1 day THEN exit", []Rule{{When: Condition{Event: EventNoEngagement, Hours: 24}, Then: Action{Type: ActionExit}}}},
+		{"IF no_engagement_for 2 weeks email 3 THEN exit", []Rule{{When: Condition{Event: EventNoEngagement, Email: 3, Hours: 336}, Then: Action{Type: ActionExit}}}},
+		{"IF clicked email 2 THEN exit", []Rule{{When: Condition{Event: EventClicked, Email: 2}, Then: Action{Type: ActionExit}}}},
+		{"IF opened email 1 within 48 hours THEN exit", []Rule{{When: Condition{Event: EventOpened, Email: 1, Hours: 48}, Then: Action{Type: ActionExit}}}},
+		{"IF opened within 2 days email 1 THEN exit", []Rule{{When: Condition{Event: EventOpened, Email: 1, Hours: 48}, Then: Action{Type: ActionExit}}}},
+		{"IF clicked THEN jump to email 4", []Rule{{When: Condition{Event: EventClicked}, Then: Action{Type: ActionJump, Email: 4}}}},
+		{"IF clicked THEN jump email 4", []Rule{{When: Condition{Event: EventClicked}, Then: Action{Type: ActionJump, Email: 4}}}},
+		{"IF purchased THEN start vip_nurture", []Rule{{When: Condition{Event: EventPurchased}, Then: Action{Type: ActionStart, Sequence: "vip_nurture"}}}},
+		{"IF opened THEN wait 3 days", []Rule{{When: Condition{Event: EventOpened}, Then: Action{Type: ActionWait, Hours: 72}}}},
+		{"IF opened THEN wait 12 hours", []Rule{{When: Condition{Event: EventOpened}, Then: Action{Type: ActionWait, Hours: 12}}}},
+		{"IF purchased THEN exit; IF no_engagement_for 7 days THEN start re_engagement", []Rule{
+			{When: Condition{Event: EventPurchased}, Then: Action{Type: ActionExit}},
+			{When: Condition{Event: EventNoEngagement, Hours: 168}, Then: Action{Type: ActionStart, Sequence: "re_engagement"}},
+		}},
+		{"IF purchased THEN exit, IF clicked email 1 THEN jump to email 3;", []Rule{
+			{When: Condition{Event: EventPurchased}, Then: Action{Type: ActionExit}},
+			{When: Condition{Event: EventClicked, Email: 1}, Then: Action{Type: ActionJump, Email: 3}},
+		}},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.in)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Parse(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		in   string
+		rule int // the rule the error points at
+	}{
+		{"WHEN purchased THEN exit", 1},
+		{"IF views product THEN exit", 1},
+		{"IF purchased exit", 1},
+		{"IF purchased THEN", 1},
+		{"IF purchased THEN accelerate", 1},
+		{"IF purchased THEN exit now", 1},
+		{"IF clicked email THEN exit", 1},
+		{"IF clicked email 0 THEN exit", 1},
+		{"IF clicked email -2 THEN exit", 1},
+		{"IF clicked within 3 fortnights THEN exit", 1},
+		{"IF no_engagement_for THEN exit", 1},
+		{"IF no_engagement_for 7 days within 2 days THEN exit", 1},
+		{"IF clicked THEN jump to 4", 1},
+		{"IF clicked THEN jump to email", 1},
+		{"IF clicked THEN start", 1},
+		{"IF clicked THEN start Browse-Abandonment", 1},
+		{"IF clicked THEN wait", 1},
+		{"IF clicked THEN wait 0 days", 1},
+		{"IF purchased THEN exit; IF declines THEN wait 90 days", 2},
+		{"IF purchased THEN exit; Hyper-urgent based on abandonment time", 2},
+	}
+	for _, tt := range tests {
+		rules, err := Parse(tt.in)
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("Parse(%q) = %+v, %v; want a syntax error", tt.in, rules, err)
+			continue
+		}
+		if syntaxErr.Rule != tt.rule {
+			t.Errorf("Parse(%q): error points at rule %d, want %d (%v)", tt.in, syntaxErr.Rule, tt.rule, err)
+		}
+		if rules != nil {
+			t.Errorf("Parse(%q) returned rules with an error", tt.in)
+		}
+	}
+}
+
+func TestRuleString(t *testing.T) {
+	tests := []struct {
+		rule Rule
+		want string
+	}{
+		{Rule{When: Condition{Event: EventPurchased}, Then: Action{Type: ActionExit}}, "IF purchased THEN exit"},
+		{Rule{When: Condition{Event: EventClicked, Email: 3}, Then: Action{Type: ActionJump, Email: 12}}, "IF clicked email 3 THEN jump to email 12"},
+		{Rule{When: Condition{Event: EventOpened, Email: 1, Hours: 30}, Then: Action{Type: ActionWait, Hours: 48}}, "IF opened email 1 within 30 hours THEN wait 2 days"},
+		{Rule{When: Condition{Event: EventNoEngagement, Hours: 336}, Then: Action{Type: ActionStart, Sequence: "win_back"}}, "IF no_engagement_for 2 weeks THEN start win_back"},
+		{Rule{When: Condition{Event: EventNoEngagement, Email: 2, Hours: 24}, Then: Action{Type: ActionExit}}, "IF no_engagement_for 1 day email 2 THEN exit"},
+	}
+	var rules []Rule
+	for _, tt := range tests {
+		if got := tt.rule.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+		rules = append(rules, tt.rule)
+	}
+
+	// Format and Parse round-trip
+	text := Format(rules)
+	got, err := Parse(text)
+	if err != nil {
+		t.Fatalf("Parse(%q): %v", text, err)
+	}
+	if !reflect.DeepEqual(got, rules) {
+		t.Errorf("Parse(Format(rules)) = %+v, want %+v", got, rules)
+	}
+}
@@ -0,0 +1,209 @@
+// Package eml builds RFC 5322 messages from rendered journey emails, so
+// they can be opened in real mail clients or handed to an SMTP server.
+package eml
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"JourneyBuilder/internal/journey"
+	"JourneyBuilder/internal/render"
+)
+
+// Default addresses for previews; example.com never receives mail.
+const (
+	defaultFrom = "JourneyBuilder Preview <preview@example.com>"
+	defaultTo   = "Preview Recipient <recipient@example.com>"
+)
+
+// Config holds the envelope details shared by every message of a journey.
+type Config struct {
+	From   string // From header; defaultFrom when empty
+	To     string // To header; defaultTo when empty
+	Render render.Options
+}
+
+// ConfigFromEnv reads EMAIL_FROM and EMAIL_TO, plus the render options
+// (see render.OptionsFromEnv).
+func ConfigFromEnv() Config {
+	return Config{
+		From:   os.Getenv("EMAIL_FROM"),
+		To:     os.Getenv("EMAIL_TO"),
+		Render: render.OptionsFromEnv(),
+	}
+}
+
+// Addresses parses the From and To addresses, applying the defaults.
+func (c Config) Addresses() (from, to *mail.Address, err error) {
+	fromHeader, toHeader := c.From, c.To
+	if fromHeader == "" {
+		fromHeader = defaultFrom
+	}
+	if toHeader == "" {
+		toHeader = defaultTo
+	}
+	if from, err = mail.ParseAddress(fromHeader); err != nil {
+		return nil, nil, fmt.Errorf("invalid From address %q: %w", fromHeader, err)
+	}
+	if to, err = mail.ParseAddress(toHeader); err != nil {
+		return nil, nil, fmt.Errorf("invalid To address %q: %w", toHeader, err)
+	}
+	return from, to, nil
+}
+
+// Message is one journey email ready to send.
+type Message struct {
+	Email    render.Email
+	Filename string // e.g. "01-you-left-something-behind.eml"
+	Date     time.Time
+	Data     []byte // the RFC 5322 message
+}
+
+// Build renders every email of j and wraps each in a multipart/alternative
+// message dated start plus the email's send offset.
+func Build(j *journey.Journey, cfg Config, start time.Time) ([]Message, error) {
+	from, to, err := cfg.Addresses()
+	if err != nil {
+		return nil, err
+	}
+	emails, err := render.RenderJourney(j, cfg.Render)
+	if err != nil {
+		return nil, err
+	}
+	offsets := make(map[int]time.Duration, len(j.Emails))
+	for _, e := range j.Emails {
+		offsets[e.Number] = e.SendOffset()
+	}
+
+	messages := make([]Message, len(emails))
+	for i, e := range emails {
+		date := start.Add(offsets[e.Number])
+		id := fmt.Sprintf("<%s.email-%d.%d@%s>", journeyRef(j), e.Number, start.Unix(), domain(from))
+		data, err := encode(e, from, to, cfg.Render.UnsubscribeURL, date, id)
+		if err != nil {
+			return nil, err
+		}
+		messages[i] = Message{
+			Email:    e,
+			Filename: fmt.Sprintf("%02d-%s.eml", e.Number, slug(e.Subject)),
+			Date:     date,
+			Data:     data,
+		}
+	}
+	return messages, nil
+}
+
+// Zip packs the messages of j into a zip archive, one .eml file per email.
+func Zip(j *journey.Journey, cfg Config, start time.Time) ([]byte, error) {
+	messages, err := Build(j, cfg, start)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, m := range messages {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: m.Filename, Method: zip.Deflate, Modified: m.Date})
+		if err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", m.Filename, err)
+		}
+		if _, err := w.Write(m.Data); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", m.Filename, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write zip: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// encode writes the headers and the text and HTML alternatives, both
+// quoted-printable so long lines survive SMTP.
+func encode(e render.Email, from, to *mail.Address, unsubscribe string, date time.Time, id string) ([]byte, error) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	// "=_" never occurs in quoted-printable output, so the boundary cannot
+	// collide with the parts, and hashing them keeps exports reproducible.
+	sum := sha256.Sum256([]byte(e.Text + e.HTML))
+	if err := mw.SetBoundary("=_" + hex.EncodeToString(sum[:12])); err != nil {
+		return nil, err
+	}
+	for _, part := range []struct{ contentType, content string }{
+		{"text/plain; charset=utf-8", e.Text},
+		{"text/html; charset=utf-8", e.HTML},
+	} {
+		w, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(w)
+		if _, err := qp.Write([]byte(part.content)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	var msg bytes.Buffer
+	header := func(name, value string) { fmt.Fprintf(&msg, "%s: %s\r\n", name, value) }
+	header("From", from.String())
+	header("To", to.String())
+	header("Subject", mime.QEncoding.Encode("utf-8", e.Subject))
+	header("Date", date.Format(time.RFC1123Z))
+	header("Message-ID", id)
+	header("MIME-Version", "1.0")
+	header("List-Unsubscribe", "<"+unsubscribe+">")
+	if strings.HasPrefix(strings.ToLower(unsubscribe), "https:") {
+		// RFC 8058 one-click unsubscribe, required by bulk sender rules
+		header("List-Unsubscribe-Post", "List-Unsubscribe=One-Click")
+	}
+	header("Content-Type", `multipart/alternative; boundary="`+mw.Boundary()+`"`)
+	msg.WriteString("\r\n")
+	msg.Write(body.Bytes())
+	return msg.Bytes(), nil
+}
+
+// journeyRef identifies the journey in Message-IDs.
+func journeyRef(j *journey.Journey) string {
+	if j.ID == "" {
+		return "journey"
+	}
+	return fmt.Sprintf("journey-%s-v%d", j.ID, j.Version)
+}
+
+func domain(addr *mail.Address) string {
+	return addr.Address[strings.LastIndex(addr.Address, "@")+1:]
+}
+
+var nonSlug = regexp.MustCompile(`[^a-z0-9]+`)
+
+// slug turns a subject into a file name part, e.g. "10% off ends tonight"
+// into "10-off-ends-tonight".
+func slug(subject string) string {
+	s := strings.Trim(nonSlug.ReplaceAllString(strings.ToLower(subject), "-"), "-")
+	if len(s) > 50 {
+		s = strings.TrimRight(s[:50], "-")
+	}
+	if s == "" {
+		return "email"
+	}
+	return s
+}
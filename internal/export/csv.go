@@ -0,0 +1,72 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"JourneyBuilder/internal/journey"
+	"JourneyBuilder/internal/render"
+)
+
+// csvHeader names the CSV columns.
+var csvHeader = []string{"number", "send_offset_hours", "subject", "preview_text", "body", "framework"}
+
+// CSV converts a journey into a flat spreadsheet with one row per email,
+// for ESPs without a flow import and for reviewing copy outside the app.
+// Bodies are converted from markdown to plain text. Branches have no
+// place in a flat list and are left out. Text cells that a spreadsheet
+// would run as a formula are prefixed with a single quote (see csvText).
+type CSV struct{}
+
+// Export returns the rows ordered by email number, after a header row.
+func (CSV) Export(j *journey.Journey) ([]byte, []string, error) {
+	if err := j.Validate(); err != nil {
+		return nil, nil, err
+	}
+	emails := append([]journey.Email(nil), j.Emails...)
+	sort.SliceStable(emails, func(a, b int) bool { return emails[a].Number < emails[b].Number })
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write(csvHeader)
+	for _, email := range emails {
+		w.Write([]string{
+			strconv.Itoa(email.Number),
+			strconv.Itoa(int(email.SendOffset() / time.Hour)),
+			csvText(email.Subject),
+			csvText(email.PreviewText),
+			csvText(render.PlainText(email.Body)),
+			csvText(email.Framework),
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, nil, fmt.Errorf("failed to encode CSV: %w", err)
+	}
+
+	var warnings []string
+	if len(j.Branches) > 0 {
+		warnings = append(warnings, fmt.Sprintf("the CSV lists emails only; %d branching rules are not included", len(j.Branches)))
+	}
+	return buf.Bytes(), warnings, nil
+}
+
+// csvText neutralises a cell that Excel, Sheets or LibreOffice would
+// evaluate as a formula (one starting with =, +, -, @, a tab or a carriage
+// return) by prefixing it with a single quote, which spreadsheets show as
+// text and hide. Copy that merely contains those characters is unchanged.
+func csvText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+func (CSV) ContentType() string { return "text/csv; charset=utf-8" }
+
+func (CSV) Extension() string { return ".csv" }
@@ -0,0 +1,42 @@
+package export
+
+import (
+	"fmt"
+	"time"
+
+	"JourneyBuilder/internal/eml"
+	"JourneyBuilder/internal/journey"
+)
+
+// EML packs a journey into a zip of .eml files, one multipart/alternative
+// message per email, for checking rendering and headers in real mail
+// clients before anything goes to an ESP. Unlike the ESP formats it needs
+// real footer details: export fails without a physical address and an
+// unsubscribe URL.
+type EML struct {
+	Config eml.Config
+	// Start dates the first message; messages are dated by send offset
+	// from it. Now when zero.
+	Start time.Time
+}
+
+// Export returns the zip archive. Branches are not included.
+func (x EML) Export(j *journey.Journey) ([]byte, []string, error) {
+	start := x.Start
+	if start.IsZero() {
+		start = time.Now()
+	}
+	data, err := eml.Zip(j, x.Config, start)
+	if err != nil {
+		return nil, nil, err
+	}
+	var warnings []string
+	if len(j.Branches) > 0 {
+		warnings = append(warnings, fmt.Sprintf("the messages follow the default order; %d branching rules are not included", len(j.Branches)))
+	}
+	return data, warnings, nil
+}
+
+func (EML) ContentType() string { return "application/zip" }
+
+func (EML) Extension() string { return ".eml.zip" }
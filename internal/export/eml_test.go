@@ -0,0 +1,101 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"testing"
+	"time"
+
+	"JourneyBuilder/internal/eml"
+	"JourneyBuilder/internal/render"
+)
+
+func TestEMLExport(t *testing.T) {
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	exporter := EML{Start: start, Config: eml.Config{
+		From:   "Acme <news@acme.example>",
+		Render: render.Options{PhysicalAddress: "1 Main St, Springfield", UnsubscribeURL: "https://acme.example/unsubscribe"},
+	}}
+	data, _, err := exporter.Export(loadJourneys(t)["cart_recovery"])
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		name, subject string
+		date          time.Time
+	}{
+		{"01-you-left-something-behind.eml", "You left something behind", start.Add(time.Hour)},
+		{"02-still-thinking-it-over.eml", "Still thinking it over?", start.Add(12 * time.Hour)},
+		{"03-10-off-ends-tonight.eml", "10% off ends tonight", start.Add(24 * time.Hour)},
+	}
+	if len(zr.File) != len(want) {
+		t.Fatalf("got %d files, want %d", len(zr.File), len(want))
+	}
+	for i, f := range zr.File {
+		if f.Name != want[i].name {
+			t.Errorf("file %d is %s, want %s", i, f.Name, want[i].name)
+		}
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		msg, err := mail.ReadMessage(rc)
+		if err != nil {
+			t.Fatalf("%s: %v", f.Name, err)
+		}
+		if got := msg.Header.Get("Subject"); got != want[i].subject {
+			t.Errorf("%s: Subject = %q, want %q", f.Name, got, want[i].subject)
+		}
+		if got, _ := msg.Header.Date(); !got.Equal(want[i].date) {
+			t.Errorf("%s: Date = %v, want %v", f.Name, got, want[i].date)
+		}
+		if got := msg.Header.Get("List-Unsubscribe"); got != "<https://acme.example/unsubscribe>" {
+			t.Errorf("%s: List-Unsubscribe = %q", f.Name, got)
+		}
+		if msg.Header.Get("List-Unsubscribe-Post") != "List-Unsubscribe=One-Click" {
+			t.Errorf("%s: no one-click List-Unsubscribe-Post header", f.Name)
+		}
+
+		mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+		if err != nil || mediaType != "multipart/alternative" {
+			t.Fatalf("%s: Content-Type %q: %v", f.Name, msg.Header.Get("Content-Type"), err)
+		}
+		mr := multipart.NewReader(msg.Body, params["boundary"])
+		var types []string
+		for {
+			part, err := mr.NextPart() // decodes quoted-printable
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				t.Fatalf("%s: %v", f.Name, err)
+			}
+			body, _ := io.ReadAll(part)
+			if !bytes.Contains(body, []byte("1 Main St, Springfield")) {
+				t.Errorf("%s: %s part has no physical address", f.Name, part.Header.Get("Content-Type"))
+			}
+			types = append(types, part.Header.Get("Content-Type"))
+		}
+		if len(types) != 2 || types[0] != "text/plain; charset=utf-8" || types[1] != "text/html; charset=utf-8" {
+			t.Errorf("%s: parts %v, want text then HTML", f.Name, types)
+		}
+		rc.Close()
+	}
+}
+
+func TestEMLRequiresFooter(t *testing.T) {
+	_, _, err := EML{}.Export(loadJourneys(t)["cart_recovery"])
+	if !errors.Is(err, render.ErrNotCompliant) {
+		t.Errorf("Export without footer details = %v, want ErrNotCompliant", err)
+	}
+}
@@ -0,0 +1,62 @@
+// Package export turns journeys into files that email service providers
+// can import, so a generated sequence does not have to be rebuilt by hand.
+package export
+
+import (
+	"errors"
+	"fmt"
+
+	"JourneyBuilder/internal/eml"
+	"JourneyBuilder/internal/journey"
+)
+
+// Export formats accepted by New.
+const (
+	FormatKlaviyo   = "klaviyo"
+	FormatMailchimp = "mailchimp"
+	FormatCSV       = "csv"
+	FormatEML       = "eml"
+)
+
+// Formats lists the export formats in the order they are offered.
+var Formats = []string{FormatKlaviyo, FormatMailchimp, FormatCSV, FormatEML}
+
+// ErrUnknownFormat is returned by New for formats it does not know.
+var ErrUnknownFormat = errors.New("unknown export format")
+
+// Exporter converts a journey into one email service provider's import
+// format.
+type Exporter interface {
+	// Export returns the file contents, plus a warning for every part of
+	// the journey the format cannot express. It fails for invalid journeys.
+	Export(j *journey.Journey) ([]byte, []string, error)
+	// ContentType is the MIME type of the exported file.
+	ContentType() string
+	// Extension is the file name suffix, e.g. ".klaviyo.json".
+	Extension() string
+}
+
+var (
+	_ Exporter = Klaviyo{}
+	_ Exporter = Mailchimp{}
+	_ Exporter = CSV{}
+	_ Exporter = EML{}
+)
+
+// New returns the exporter for format with placeholder account settings.
+// The eml format takes its sender and footer details from the environment
+// (see eml.ConfigFromEnv).
+func New(format string) (Exporter, error) {
+	switch format {
+	case FormatKlaviyo:
+		return Klaviyo{}, nil
+	case FormatMailchimp:
+		return Mailchimp{}, nil
+	case FormatCSV:
+		return CSV{}, nil
+	case FormatEML:
+		return EML{Config: eml.ConfigFromEnv()}, nil
+	default:
+		return nil, fmt.Errorf("%w %q", ErrUnknownFormat, format)
+	}
+}
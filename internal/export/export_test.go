@@ -0,0 +1,114 @@
+package export
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"JourneyBuilder/internal/journey"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files in testdata")
+
+// loadJourneys reads every testdata/<name>.journey.json.
+func loadJourneys(t *testing.T) map[string]*journey.Journey {
+	t.Helper()
+	paths, err := filepath.Glob(filepath.Join("testdata", "*.journey.json"))
+	if err != nil || len(paths) == 0 {
+		t.Fatalf("no test journeys found: %v", err)
+	}
+	journeys := make(map[string]*journey.Journey, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var j journey.Journey
+		if err := json.Unmarshal(data, &j); err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		journeys[strings.TrimSuffix(filepath.Base(path), ".journey.json")] = &j
+	}
+	return journeys
+}
+
+// checkGolden compares got with testdata/name, or rewrites it with -update.
+func checkGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run go test ./internal/export -update to create it)", err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("%s differs from the golden file:\ngot:\n%s\nwant:\n%s", path, got, want)
+	}
+}
+
+func TestExportGolden(t *testing.T) {
+	journeys := loadJourneys(t)
+	for _, format := range Formats {
+		if format == FormatEML {
+			continue // binary; see TestEMLExport
+		}
+		exporter, err := New(format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for name, j := range journeys {
+			t.Run(format+"/"+name, func(t *testing.T) {
+				data, warnings, err := exporter.Export(j)
+				if err != nil {
+					t.Fatalf("Export: %v", err)
+				}
+				checkGolden(t, name+exporter.Extension(), data)
+				checkGolden(t, name+"."+format+".warnings", []byte(strings.Join(append(warnings, ""), "\n")))
+			})
+		}
+	}
+}
+
+func TestExportRejectsInvalidJourney(t *testing.T) {
+	for _, format := range Formats {
+		exporter, _ := New(format)
+		if _, _, err := exporter.Export(&journey.Journey{}); err == nil {
+			t.Errorf("%s: export of a journey without emails succeeded", format)
+		}
+	}
+}
+
+func TestNewUnknownFormat(t *testing.T) {
+	if _, err := New("hubspot"); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("New(hubspot) = %v, want ErrUnknownFormat", err)
+	}
+}
+
+func TestCSVText(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"=SUM(A1:A9)", "'=SUM(A1:A9)"},
+		{"+1 reason", "'+1 reason"},
+		{"-10% today", "'-10% today"},
+		{"@mention", "'@mention"},
+		{"\tTabbed", "'\tTabbed"},
+		{"Save 2+2=4 ways", "Save 2+2=4 ways"},
+		{"'Quoted'", "'Quoted'"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := csvText(tt.in); got != tt.want {
+			t.Errorf("csvText(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
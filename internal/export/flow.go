@@ -0,0 +1,256 @@
+package export
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"JourneyBuilder/internal/branching"
+	"JourneyBuilder/internal/journey"
+)
+
+// maxFlowSteps bounds the flow size. ESP flows are trees, so wait and jump
+// branches copy the rest of the flow; this stops a pathological set of
+// rules from producing a flow nobody could review.
+const maxFlowSteps = 500
+
+// ErrTooComplex is returned when a journey's branches would expand into an unreasonably large flow.
+var ErrTooComplex = errors.New("journey branches are too complex to export")
+
+// stepKind is the type of a flowStep.
+type stepKind int
+
+const (
+	stepDelay stepKind = iota // wait before the next step
+	stepSend                  // send an email
+	stepSplit                 // go to ifTrue or ifFalse depending on the condition
+)
+
+// flowStep is one action of a planned flow. Steps refer to each other by
+// number (index + 1); 0 ends the flow.
+type flowStep struct {
+	kind    stepKind
+	delay   time.Duration // stepDelay
+	email   journey.Email // stepSend
+	cond    flowCondition // stepSplit
+	next    int           // stepDelay, stepSend
+	ifTrue  int           // stepSplit
+	ifFalse int           // stepSplit
+}
+
+// flowCondition is what a split checks.
+type flowCondition struct {
+	event branching.Event
+	email int // the watched email's number; 0 for any email since the flow started
+	// hours is the quiet period for no_engagement_for. For other events
+	// with an email, it is the time since that email was sent.
+	hours int
+}
+
+// flowPlan lays out a journey as the tree of delays, sends and splits that
+// ESP flow builders use. Steps are numbered in the order they are created,
+// which follows the flow from the top.
+type flowPlan struct {
+	provider string          // for warnings, e.g. "Klaviyo"
+	emails   []journey.Email // ordered by number
+	rules    []branching.Rule
+	steps    []flowStep
+	entry    int
+	// exitOn lists events that end the flow whenever they happen, for
+	// providers that check flow-level filters before every step.
+	exitOn   []branching.Event
+	warnings []string
+	overflow bool
+}
+
+// planFlow plans j for provider. Up to maxExits exit rules that apply at
+// any time become flow-level exits (negative for no limit); other branches
+// become splits placed before the first email they can affect. Rules no
+// flow can express, such as starting another sequence or jumping back,
+// are left out with a warning.
+func planFlow(j *journey.Journey, provider string, maxExits int) (*flowPlan, error) {
+	if err := j.Validate(); err != nil {
+		return nil, err
+	}
+	emails := append([]journey.Email(nil), j.Emails...)
+	sort.SliceStable(emails, func(a, b int) bool { return emails[a].Number < emails[b].Number })
+
+	p := &flowPlan{provider: provider, emails: emails}
+	p.sortRules(j.Branches, maxExits)
+	p.entry = p.checkpoint(0, 0)
+	if p.overflow {
+		return nil, fmt.Errorf("%w: more than %d %s actions", ErrTooComplex, maxFlowSteps, provider)
+	}
+	return p, nil
+}
+
+// sortRules splits the branches into flow-level exits and the rules that
+// need splits, warning about the ones a flow cannot express.
+func (p *flowPlan) sortRules(rules []branching.Rule, maxExits int) {
+	for _, r := range rules {
+		w := r.When
+		switch {
+		case r.Then.Type == branching.ActionStart:
+			p.warn(fmt.Sprintf("%q: %s flows cannot start another sequence; add the %s flow's trigger by hand", r.String(), p.provider, r.Then.Sequence))
+		case r.Then.Type == branching.ActionJump && p.index(r.Then.Email) <= p.trigger(r):
+			p.warn(fmt.Sprintf("%q: %s flows cannot jump back to an earlier email", r.String(), p.provider))
+		case r.Then.Type == branching.ActionExit && w.Event != branching.EventNoEngagement && w.Email == 0 && w.Hours == 0 &&
+			(maxExits < 0 || len(p.exitOn) < maxExits):
+			p.exitOn = append(p.exitOn, w.Event)
+		default:
+			if w.Event != branching.EventNoEngagement && w.Hours > 0 {
+				p.warn(fmt.Sprintf("%q: %s splits cannot look back to a window after an earlier send; the split checks for any %s event since the email instead", r.String(), p.provider, w.Event))
+			}
+			p.rules = append(p.rules, r)
+		}
+	}
+}
+
+// checkpoint plans the flow from email index i on, waiting its usual gap
+// minus waited, the time already spent since the previous send.
+func (p *flowPlan) checkpoint(i int, waited time.Duration) int {
+	if i >= len(p.emails) {
+		return 0
+	}
+	return p.delay(p.gap(i)-waited, func() int {
+		return p.splits(i, 0, func() int {
+			return p.send(i, func() int { return p.checkpoint(i+1, 0) })
+		})
+	})
+}
+
+// splits adds a split for every rule from rule index r on that applies
+// before email index i, then continues with next.
+func (p *flowPlan) splits(i, r int, next func() int) int {
+	for ; r < len(p.rules); r++ {
+		if cond, ok := p.condition(p.rules[r], i); ok {
+			rule, rest := p.rules[r], r+1
+			return p.split(cond, func() int {
+				switch rule.Then.Type {
+				case branching.ActionJump:
+					return p.checkpoint(p.index(rule.Then.Email), p.gap(i))
+				case branching.ActionWait:
+					return p.delay(time.Duration(rule.Then.Hours)*time.Hour, func() int { return p.splits(i, rest, next) })
+				default: // exit
+					return 0
+				}
+			}, func() int { return p.splits(i, rest, next) })
+		}
+	}
+	return next()
+}
+
+// condition returns the split condition for rule r before email index i,
+// or false if the rule does not apply there. Event rules are checked before
+// every email after the one they watch (only the first, for jumps and
+// waits); no_engagement_for rules once the quiet period can have passed.
+// A jump only applies where its target is still ahead.
+func (p *flowPlan) condition(r branching.Rule, i int) (flowCondition, bool) {
+	if i == 0 {
+		return flowCondition{}, false // nothing to react to before the first email
+	}
+	w := r.When
+	t := p.trigger(r)
+	once := r.Then.Type != branching.ActionExit
+	if r.Then.Type == branching.ActionJump && p.index(r.Then.Email) <= i {
+		return flowCondition{}, false // the target is already next, or behind
+	}
+
+	if w.Event == branching.EventNoEngagement {
+		quiet := time.Duration(w.Hours) * time.Hour
+		if p.elapsed(r, i) < quiet || once && p.elapsed(r, i-1) >= quiet {
+			return flowCondition{}, false
+		}
+		return flowCondition{event: w.Event, email: w.Email, hours: w.Hours}, true
+	}
+
+	if i <= t || once && i != t+1 {
+		return flowCondition{}, false
+	}
+	cond := flowCondition{event: w.Event, email: w.Email}
+	if w.Email != 0 {
+		cond.hours = max(int((p.offset(i)-p.offset(t))/time.Hour), 1)
+	}
+	return cond, true
+}
+
+// elapsed is the quiet time a no_engagement_for rule has reached at the
+// checkpoint before email index i.
+func (p *flowPlan) elapsed(r branching.Rule, i int) time.Duration {
+	elapsed := p.offset(i)
+	if r.When.Email != 0 {
+		elapsed -= p.offset(p.trigger(r))
+	}
+	return elapsed
+}
+
+// trigger is the index of the email a rule watches, or 0 for any email.
+func (p *flowPlan) trigger(r branching.Rule) int {
+	if r.When.Email == 0 {
+		return 0
+	}
+	return p.index(r.When.Email)
+}
+
+func (p *flowPlan) index(number int) int {
+	for i, email := range p.emails {
+		if email.Number == number {
+			return i
+		}
+	}
+	return -1
+}
+
+func (p *flowPlan) offset(i int) time.Duration {
+	return p.emails[i].SendOffset()
+}
+
+// gap is how long email index i waits after the email before it.
+func (p *flowPlan) gap(i int) time.Duration {
+	if i == 0 {
+		return p.offset(0)
+	}
+	return max(p.offset(i)-p.offset(i-1), 0)
+}
+
+// add appends a step and returns its number.
+func (p *flowPlan) add(step flowStep) int {
+	if len(p.steps) >= maxFlowSteps {
+		p.overflow = true
+	}
+	p.steps = append(p.steps, step)
+	return len(p.steps)
+}
+
+func (p *flowPlan) delay(d time.Duration, next func() int) int {
+	if d <= 0 || p.overflow {
+		return next()
+	}
+	n := p.add(flowStep{kind: stepDelay, delay: d})
+	p.steps[n-1].next = next()
+	return n
+}
+
+func (p *flowPlan) send(i int, next func() int) int {
+	if p.overflow {
+		return 0
+	}
+	n := p.add(flowStep{kind: stepSend, email: p.emails[i]})
+	p.steps[n-1].next = next()
+	return n
+}
+
+func (p *flowPlan) split(cond flowCondition, onTrue, onFalse func() int) int {
+	if p.overflow {
+		return 0
+	}
+	n := p.add(flowStep{kind: stepSplit, cond: cond})
+	p.steps[n-1].ifTrue = onTrue()
+	p.steps[n-1].ifFalse = onFalse()
+	return n
+}
+
+func (p *flowPlan) warn(msg string) {
+	p.warnings = append(p.warnings, msg)
+}
@@ -0,0 +1,267 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"JourneyBuilder/internal/branching"
+	"JourneyBuilder/internal/journey"
+)
+
+// Klaviyo metrics used by conditional splits and flow filters.
+const (
+	metricOpened    = "Opened Email"
+	metricClicked   = "Clicked Email"
+	metricPurchased = "Placed Order"
+)
+
+// Klaviyo converts a journey into a Klaviyo flow definition: the body of a
+// Create Flow request. Emails become send-email actions preceded by
+// time-delay actions. Exit rules that apply at any time become the flow's
+// profile filter; other branches become conditional splits placed before
+// the first email they can affect. Rules Klaviyo cannot express, such as
+// starting another sequence or jumping back, are reported as warnings.
+type Klaviyo struct {
+	// MetricIDs maps Klaviyo metric names ("Opened Email", "Clicked Email",
+	// "Placed Order") to the account's metric IDs. Missing metrics are
+	// exported as placeholders such as "OPENED_EMAIL_METRIC_ID".
+	MetricIDs map[string]string
+	// ListID is the list that triggers the flow; "LIST_ID" when empty.
+	ListID string
+}
+
+// Export returns the flow definition as indented JSON, plus a warning for
+// every branch that could not be expressed.
+func (k Klaviyo) Export(j *journey.Journey) ([]byte, []string, error) {
+	plan, err := planFlow(j, "Klaviyo", -1)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var flowFilter *klaviyoFilter
+	if len(plan.exitOn) > 0 {
+		// The flow filter is checked before every action
+		flowFilter = &klaviyoFilter{}
+		for _, event := range plan.exitOn {
+			flowFilter.ConditionGroups = append(flowFilter.ConditionGroups, klaviyoConditionGroup{
+				Conditions: []klaviyoCondition{k.metricCondition(event, "equals", klaviyoTimeframe{Type: "date", Operator: "flow-start"})},
+			})
+		}
+	}
+	actions := make([]klaviyoAction, len(plan.steps))
+	for n, step := range plan.steps {
+		actions[n] = k.action(n+1, step)
+	}
+
+	listID := k.ListID
+	if listID == "" {
+		listID = "LIST_ID"
+	}
+	flow := klaviyoFlow{Data: klaviyoFlowData{
+		Type: "flow",
+		Attributes: klaviyoFlowAttributes{
+			Name: flowName(j),
+			Definition: klaviyoDefinition{
+				Triggers:      []klaviyoTrigger{{Type: "list", ID: listID}},
+				ProfileFilter: flowFilter,
+				EntryActionID: actionID(plan.entry),
+				Actions:       actions,
+			},
+		},
+	}}
+	data, err := json.MarshalIndent(flow, "", "  ")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode Klaviyo flow: %w", err)
+	}
+	return append(data, '\n'), plan.warnings, nil
+}
+
+func (Klaviyo) ContentType() string { return "application/json" }
+
+func (Klaviyo) Extension() string { return ".klaviyo.json" }
+
+// flowName names the flow after the journey's outcome and vertical.
+func flowName(j *journey.Journey) string {
+	name := strings.TrimSpace(j.Outcome)
+	if name == "" {
+		name = "Journey"
+		if j.ID != "" {
+			name += " " + j.ID
+		}
+	}
+	if j.Vertical != "" {
+		name += " (" + j.Vertical + ")"
+	}
+	if j.Version > 0 {
+		name += fmt.Sprintf(" v%d", j.Version)
+	}
+	return name
+}
+
+// Klaviyo flow definition, as accepted by the Create Flow endpoint.
+type (
+	klaviyoFlow struct {
+		Data klaviyoFlowData `json:"data"`
+	}
+	klaviyoFlowData struct {
+		Type       string                `json:"type"`
+		Attributes klaviyoFlowAttributes `json:"attributes"`
+	}
+	klaviyoFlowAttributes struct {
+		Name       string            `json:"name"`
+		Definition klaviyoDefinition `json:"definition"`
+	}
+	klaviyoDefinition struct {
+		Triggers      []klaviyoTrigger `json:"triggers"`
+		ProfileFilter *klaviyoFilter   `json:"profile_filter"`
+		EntryActionID string           `json:"entry_action_id"`
+		Actions       []klaviyoAction  `json:"actions"`
+	}
+	klaviyoTrigger struct {
+		Type string `json:"type"`
+		ID   string `json:"id"`
+	}
+	klaviyoAction struct {
+		TemporaryID string             `json:"temporary_id"`
+		Type        string             `json:"type"`
+		Links       map[string]*string `json:"links"`
+		Data        any                `json:"data"`
+	}
+	klaviyoFilter struct {
+		ConditionGroups []klaviyoConditionGroup `json:"condition_groups"` // all groups must match
+	}
+	klaviyoConditionGroup struct {
+		Conditions []klaviyoCondition `json:"conditions"` // any condition may match
+	}
+	klaviyoCondition struct {
+		Type              string           `json:"type"`
+		MetricID          string           `json:"metric_id"`
+		Measurement       string           `json:"measurement"`
+		MeasurementFilter klaviyoNumeric   `json:"measurement_filter"`
+		TimeframeFilter   klaviyoTimeframe `json:"timeframe_filter"`
+		MetricFilters     []any            `json:"metric_filters"`
+	}
+	klaviyoNumeric struct {
+		Type     string `json:"type"`
+		Operator string `json:"operator"`
+		Value    int    `json:"value"`
+	}
+	klaviyoTimeframe struct {
+		Type     string `json:"type"`
+		Operator string `json:"operator"`
+		Quantity int    `json:"quantity,omitempty"`
+		Unit     string `json:"unit,omitempty"`
+	}
+	klaviyoDelay struct {
+		Unit               string   `json:"unit"`
+		Value              int      `json:"value"`
+		SecondaryValue     *int     `json:"secondary_value"`
+		Timezone           string   `json:"timezone"`
+		DelayUntilTime     *string  `json:"delay_until_time"`
+		DelayUntilWeekdays []string `json:"delay_until_weekdays"`
+	}
+	klaviyoSendEmail struct {
+		Message klaviyoMessage `json:"message"`
+		Status  string         `json:"status"`
+	}
+	klaviyoMessage struct {
+		Name                string  `json:"name"`
+		SubjectLine         string  `json:"subject_line"`
+		PreviewText         string  `json:"preview_text"`
+		TemplateID          *string `json:"template_id"`
+		SmartSendingEnabled bool    `json:"smart_sending_enabled"`
+	}
+)
+
+var allWeekdays = []string{"monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"}
+
+// action renders plan step n.
+func (k Klaviyo) action(n int, step flowStep) klaviyoAction {
+	a := klaviyoAction{TemporaryID: strconv.Itoa(n), Links: map[string]*string{}}
+	switch step.kind {
+	case stepDelay:
+		data := klaviyoDelay{Unit: "hours", Value: int(step.delay / time.Hour), Timezone: "profile", DelayUntilWeekdays: allWeekdays}
+		if step.delay%(24*time.Hour) == 0 {
+			data.Unit, data.Value = "days", int(step.delay/(24*time.Hour))
+		}
+		a.Type, a.Data = "time-delay", data
+		a.Links["next"] = link(step.next)
+	case stepSend:
+		a.Type, a.Data = "send-email", klaviyoSendEmail{
+			Message: klaviyoMessage{
+				Name:        fmt.Sprintf("Email %d: %s", step.email.Number, step.email.Subject),
+				SubjectLine: step.email.Subject,
+				PreviewText: step.email.PreviewText,
+				// Emails hours apart must not be suppressed by smart sending
+				SmartSendingEnabled: false,
+			},
+			Status: "draft",
+		}
+		a.Links["next"] = link(step.next)
+	case stepSplit:
+		a.Type, a.Data = "conditional-split", map[string]any{"profile_filter": k.splitFilter(step.cond)}
+		a.Links["next_if_true"] = link(step.ifTrue)
+		a.Links["next_if_false"] = link(step.ifFalse)
+	}
+	return a
+}
+
+// splitFilter expresses a split condition as a profile filter. Quiet
+// periods check that nothing was opened or clicked in the last hours;
+// events are counted since the watched email was sent, or since the flow
+// started.
+func (k Klaviyo) splitFilter(c flowCondition) *klaviyoFilter {
+	if c.event == branching.EventNoEngagement {
+		frame := klaviyoTimeframe{Type: "date", Operator: "in-the-last", Quantity: c.hours, Unit: "hour"}
+		return &klaviyoFilter{ConditionGroups: []klaviyoConditionGroup{
+			{Conditions: []klaviyoCondition{k.metricCondition(branching.EventOpened, "equals", frame)}},
+			{Conditions: []klaviyoCondition{k.metricCondition(branching.EventClicked, "equals", frame)}},
+		}}
+	}
+	frame := klaviyoTimeframe{Type: "date", Operator: "flow-start"}
+	if c.email != 0 {
+		frame = klaviyoTimeframe{Type: "date", Operator: "in-the-last", Quantity: c.hours, Unit: "hour"}
+	}
+	return &klaviyoFilter{ConditionGroups: []klaviyoConditionGroup{
+		{Conditions: []klaviyoCondition{k.metricCondition(c.event, "greater-than", frame)}},
+	}}
+}
+
+func (k Klaviyo) metricCondition(event branching.Event, operator string, frame klaviyoTimeframe) klaviyoCondition {
+	name := map[branching.Event]string{
+		branching.EventOpened:    metricOpened,
+		branching.EventClicked:   metricClicked,
+		branching.EventPurchased: metricPurchased,
+	}[event]
+	id := k.MetricIDs[name]
+	if id == "" {
+		id = strings.ToUpper(strings.ReplaceAll(name, " ", "_")) + "_METRIC_ID"
+	}
+	return klaviyoCondition{
+		Type:              "profile-metric",
+		MetricID:          id,
+		Measurement:       "count",
+		MeasurementFilter: klaviyoNumeric{Type: "numeric", Operator: operator, Value: 0},
+		TimeframeFilter:   frame,
+	}
+}
+
+// actionID is the temporary ID of plan step n; "" ends the flow.
+func actionID(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
+
+// link points at plan step n; step 0 ends the flow there.
+func link(n int) *string {
+	if n == 0 {
+		return nil
+	}
+	id := strconv.Itoa(n)
+	return &id
+}
@@ -0,0 +1,22 @@
+package export
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestKlaviyoMetricIDs(t *testing.T) {
+	j := loadJourneys(t)["cart_recovery"]
+	data, _, err := Klaviyo{MetricIDs: map[string]string{metricPurchased: "AbC123"}, ListID: "Xy9"}.Export(j)
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	for _, want := range []string{`"metric_id": "AbC123"`, `"id": "Xy9"`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("export does not contain %s", want)
+		}
+	}
+	if strings.Contains(string(data), "PLACED_ORDER_METRIC_ID") {
+		t.Error("placeholder used although a metric ID was given")
+	}
+}
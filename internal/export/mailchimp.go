@@ -0,0 +1,156 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"JourneyBuilder/internal/branching"
+	"JourneyBuilder/internal/journey"
+)
+
+// Mailchimp converts a journey into a Customer Journey build sheet.
+// Mailchimp's Marketing API cannot create journeys, so the export lays out
+// the journey the way the journey builder does, step by step, for whoever
+// recreates it there. Emails become send steps preceded by time delays.
+// The first exit rule that applies at any time becomes the journey's goal,
+// since Mailchimp allows only one; other branches become if/else steps
+// placed before the first email they can affect.
+type Mailchimp struct {
+	// AudienceID is the audience whose signups start the journey;
+	// "AUDIENCE_ID" when empty.
+	AudienceID string
+}
+
+// Export returns the build sheet as indented JSON, plus a warning for every
+// branch that could not be expressed.
+func (m Mailchimp) Export(j *journey.Journey) ([]byte, []string, error) {
+	plan, err := planFlow(j, "Mailchimp", 1)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	audienceID := m.AudienceID
+	if audienceID == "" {
+		audienceID = "AUDIENCE_ID"
+	}
+	sheet := mailchimpJourney{
+		JourneyName:   flowName(j),
+		AudienceID:    audienceID,
+		StartingPoint: mailchimpStartingPoint{Type: "signup", AudienceID: audienceID},
+		FirstStepID:   stepRef(plan.entry),
+		Steps:         make([]mailchimpStep, len(plan.steps)),
+	}
+	if len(plan.exitOn) > 0 {
+		goal := mailchimpCondition(flowCondition{event: plan.exitOn[0]})
+		sheet.Goal = &goal
+	}
+	for n, step := range plan.steps {
+		sheet.Steps[n] = mailchimpStepFor(n+1, step)
+	}
+
+	data, err := json.MarshalIndent(sheet, "", "  ")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode Mailchimp journey: %w", err)
+	}
+	return append(data, '\n'), plan.warnings, nil
+}
+
+func (Mailchimp) ContentType() string { return "application/json" }
+
+func (Mailchimp) Extension() string { return ".mailchimp.json" }
+
+// Mailchimp Customer Journey build sheet. Steps link to each other by ID;
+// "end" ends the journey.
+type (
+	mailchimpJourney struct {
+		JourneyName   string                 `json:"journey_name"`
+		AudienceID    string                 `json:"audience_id"`
+		StartingPoint mailchimpStartingPoint `json:"starting_point"`
+		Goal          *mailchimpRule         `json:"goal"`
+		FirstStepID   string                 `json:"first_step_id"`
+		Steps         []mailchimpStep        `json:"steps"`
+	}
+	mailchimpStartingPoint struct {
+		Type       string `json:"type"`
+		AudienceID string `json:"audience_id"`
+	}
+	mailchimpStep struct {
+		ID   string `json:"id"`
+		Type string `json:"type"`
+		// delay
+		Amount int    `json:"amount,omitempty"`
+		Unit   string `json:"unit,omitempty"`
+		// send_email
+		EmailName   string `json:"email_name,omitempty"`
+		Subject     string `json:"subject,omitempty"`
+		PreviewText string `json:"preview_text,omitempty"`
+		// if_else
+		Condition *mailchimpRule `json:"condition,omitempty"`
+		Yes       string         `json:"yes,omitempty"`
+		No        string         `json:"no,omitempty"`
+
+		Next string `json:"next,omitempty"`
+	}
+	mailchimpRule struct {
+		Type            string   `json:"type"`
+		Activities      []string `json:"activities"`
+		Email           string   `json:"email,omitempty"`
+		Since           string   `json:"since,omitempty"`
+		WithinLastHours int      `json:"within_last_hours,omitempty"`
+	}
+)
+
+// mailchimpStepFor renders plan step n.
+func mailchimpStepFor(n int, step flowStep) mailchimpStep {
+	s := mailchimpStep{ID: strconv.Itoa(n)}
+	switch step.kind {
+	case stepDelay:
+		s.Type, s.Amount, s.Unit = "delay", int(step.delay/time.Hour), "hours"
+		if step.delay%(24*time.Hour) == 0 {
+			s.Amount, s.Unit = int(step.delay/(24*time.Hour)), "days"
+		}
+		s.Next = stepRef(step.next)
+	case stepSend:
+		s.Type = "send_email"
+		s.EmailName = fmt.Sprintf("Email %d: %s", step.email.Number, step.email.Subject)
+		s.Subject, s.PreviewText = step.email.Subject, step.email.PreviewText
+		s.Next = stepRef(step.next)
+	case stepSplit:
+		rule := mailchimpCondition(step.cond)
+		s.Type, s.Condition = "if_else", &rule
+		s.Yes, s.No = stepRef(step.ifTrue), stepRef(step.ifFalse)
+	}
+	return s
+}
+
+// mailchimpCondition expresses a split condition as an if/else rule.
+// Opens and clicks of a named email need no window, since Mailchimp tracks
+// them per email; purchases are counted since that email was sent, or
+// since the contact started the journey.
+func mailchimpCondition(c flowCondition) mailchimpRule {
+	switch c.event {
+	case branching.EventNoEngagement:
+		return mailchimpRule{Type: "no_email_activity", Activities: []string{"opened", "clicked"}, WithinLastHours: c.hours}
+	case branching.EventPurchased:
+		if c.email != 0 {
+			return mailchimpRule{Type: "purchase_activity", Activities: []string{"purchased"}, WithinLastHours: c.hours}
+		}
+		return mailchimpRule{Type: "purchase_activity", Activities: []string{"purchased"}, Since: "journey_start"}
+	default:
+		rule := mailchimpRule{Type: "email_activity", Activities: []string{string(c.event)}, Email: "any email in this journey"}
+		if c.email != 0 {
+			rule.Email = fmt.Sprintf("Email %d", c.email)
+		}
+		return rule
+	}
+}
+
+// stepRef is the ID of plan step n, or "end" for step 0.
+func stepRef(n int) string {
+	if n == 0 {
+		return "end"
+	}
+	return strconv.Itoa(n)
+}
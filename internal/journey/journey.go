@@ -0,0 +1,143 @@
+package journey
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"JourneyBuilder/internal/branching"
+)
+
+// Email is one message of a journey.
+type Email struct {
+	Number         int    `json:"number"`
+	Subject        string `json:"subject"`
+	DayDelay       int    `json:"dayDelay"`       // days after the journey starts
+	SendAfterHours int    `json:"sendAfterHours"` // hours after the journey starts; DayDelay in whole days
+	PreviewText    string `json:"previewText,omitempty"`
+	Body           string `json:"body,omitempty"`
+	Framework      string `json:"framework,omitempty"`
+	CTA            string `json:"cta,omitempty"`
+}
+
+// SendOffset returns how long after the journey starts the email is sent.
+// SendAfterHours is used while it agrees with DayDelay; once DayDelay has
+// been edited on its own, the email goes out at the start of that day.
+func (e Email) SendOffset() time.Duration {
+	if e.SendAfterHours >= 0 && e.SendAfterHours/24 == e.DayDelay {
+		return time.Duration(e.SendAfterHours) * time.Hour
+	}
+	return time.Duration(e.DayDelay) * 24 * time.Hour
+}
+
+// SetSendOffset sets SendAfterHours and DayDelay from d, rounded down to the hour.
+func (e *Email) SetSendOffset(d time.Duration) {
+	e.SendAfterHours = int(d / time.Hour)
+	e.DayDelay = e.SendAfterHours / 24
+}
+
+// Journey is an email sequence generated at StepExecution, ordered by email number.
+// ID, Version, Workspace and SavedAt are set when the journey is stored;
+// ConfirmedAt is set when it is confirmed. A stored journey without
+// ConfirmedAt is a draft.
+type Journey struct {
+	ID          string     `json:"id,omitempty"`
+	Version     int        `json:"version,omitempty"`
+	Workspace   string     `json:"workspace,omitempty"`
+	SavedAt     *time.Time `json:"savedAt,omitempty"`
+	ConfirmedAt *time.Time `json:"confirmedAt,omitempty"`
+	Outcome     string     `json:"outcome,omitempty"`
+	Vertical    string     `json:"vertical,omitempty"`
+	Emails      []Email    `json:"emails"`
+	// Branches move contacts out of, within or between sequences, checked in order.
+	Branches []branching.Rule `json:"branches,omitempty"`
+}
+
+// Email returns the email with the given number, or nil.
+func (j *Journey) Email(number int) *Email {
+	for i := range j.Emails {
+		if j.Emails[i].Number == number {
+			return &j.Emails[i]
+		}
+	}
+	return nil
+}
+
+// Validate checks that the journey has emails with unique, positive numbers
+// and that its branches are complete and refer only to those emails.
+func (j *Journey) Validate() error {
+	if j == nil || len(j.Emails) == 0 {
+		return errors.New("journey has no emails")
+	}
+	seen := make(map[int]bool, len(j.Emails))
+	for _, email := range j.Emails {
+		if email.Number <= 0 {
+			return fmt.Errorf("email number %d must be positive", email.Number)
+		}
+		if seen[email.Number] {
+			return fmt.Errorf("email %d appears more than once", email.Number)
+		}
+		seen[email.Number] = true
+	}
+	for i, r := range j.Branches {
+		if err := r.Validate(); err != nil {
+			return fmt.Errorf("branch %d: %v", i+1, err)
+		}
+		for _, n := range []int{r.When.Email, r.Then.Email} {
+			if n > 0 && !seen[n] {
+				return fmt.Errorf("branch %d (%s) refers to email %d, which is not in the journey", i+1, r, n)
+			}
+		}
+	}
+	return nil
+}
+
+// SortEmails orders the emails by number.
+func (j *Journey) SortEmails() {
+	sort.SliceStable(j.Emails, func(a, b int) bool { return j.Emails[a].Number < j.Emails[b].Number })
+}
+
+// Delays returns the day delay of every email, in order.
+func (j *Journey) Delays() []int {
+	delays := make([]int, len(j.Emails))
+	for i, email := range j.Emails {
+		delays[i] = email.DayDelay
+	}
+	return delays
+}
+
+// SetSendOffsets applies offsets to the emails in order; emails beyond the
+// end of offsets are left as they are.
+func (j *Journey) SetSendOffsets(offsets []time.Duration) {
+	for i := range j.Emails {
+		if i < len(offsets) {
+			j.Emails[i].SetSendOffset(offsets[i])
+		}
+	}
+}
+
+// NormalizeDelays orders the emails and makes their delays consistent: the
+// first email goes out on day 0 and no email goes out before the one before
+// it. SendAfterHours is brought in line with DayDelay. It returns a note for
+// every day delay it changed.
+func (j *Journey) NormalizeDelays() []string {
+	j.SortEmails()
+
+	var notes []string
+	var prev time.Duration
+	for i := range j.Emails {
+		email := &j.Emails[i]
+		offset := max(email.SendOffset(), prev)
+		if i == 0 && offset >= 24*time.Hour {
+			offset = 0
+		}
+		before := email.DayDelay
+		email.SetSendOffset(offset)
+		if email.DayDelay != before {
+			notes = append(notes, fmt.Sprintf("email %d moved from day %d to day %d", email.Number, before, email.DayDelay))
+		}
+		prev = offset
+	}
+	return notes
+}
@@ -0,0 +1,64 @@
+package journey
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSendOffset(t *testing.T) {
+	tests := []struct {
+		name  string
+		email Email
+		want  time.Duration
+	}{
+		{"hours and days agree", Email{DayDelay: 0, SendAfterHours: 1}, time.Hour},
+		{"hours within the day", Email{DayDelay: 1, SendAfterHours: 36}, 36 * time.Hour},
+		{"whole days", Email{DayDelay: 3, SendAfterHours: 72}, 72 * time.Hour},
+		// Journeys saved before SendAfterHours existed decode it as 0
+		{"hours missing", Email{DayDelay: 2}, 48 * time.Hour},
+		// DayDelay edited on its own moves the send to the start of that day
+		{"day moved later", Email{DayDelay: 4, SendAfterHours: 36}, 96 * time.Hour},
+		{"day moved earlier", Email{DayDelay: 1, SendAfterHours: 72}, 24 * time.Hour},
+		{"negative hours", Email{DayDelay: 0, SendAfterHours: -5}, 0},
+	}
+	for _, tt := range tests {
+		if got := tt.email.SendOffset(); got != tt.want {
+			t.Errorf("%s: SendOffset() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSetSendOffset(t *testing.T) {
+	for _, d := range []time.Duration{0, time.Hour, 23 * time.Hour, 24 * time.Hour, 36 * time.Hour, 90 * time.Minute} {
+		var e Email
+		e.SetSendOffset(d)
+		if want := d.Truncate(time.Hour); e.SendOffset() != want {
+			t.Errorf("SetSendOffset(%v): SendOffset() = %v, want %v", d, e.SendOffset(), want)
+		}
+		if want := int(d / (24 * time.Hour)); e.DayDelay != want {
+			t.Errorf("SetSendOffset(%v): DayDelay = %d, want %d", d, e.DayDelay, want)
+		}
+	}
+}
+
+func TestNormalizeDelays(t *testing.T) {
+	j := &Journey{Emails: []Email{
+		{Number: 2, DayDelay: 1, SendAfterHours: 30},
+		{Number: 1, DayDelay: 2, SendAfterHours: 48},
+		{Number: 3, DayDelay: 1},
+	}}
+	notes := j.NormalizeDelays()
+
+	want := []time.Duration{0, 30 * time.Hour, 30 * time.Hour}
+	for i, email := range j.Emails {
+		if email.Number != i+1 {
+			t.Fatalf("email %d is number %d", i, email.Number)
+		}
+		if got := email.SendOffset(); got != want[i] {
+			t.Errorf("email %d: SendOffset() = %v, want %v", email.Number, got, want[i])
+		}
+	}
+	if len(notes) != 1 {
+		t.Errorf("notes = %q, want one note for email 1", notes)
+	}
+}
@@ -0,0 +1,171 @@
+package journey
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// firstNumber finds the first integer in a table cell such as "1", "**2**" or "Day 3".
+	firstNumber = regexp.MustCompile(`\d+`)
+
+	// sectionHeader matches "Email 2:", "### Email 2 - Subject" or "**Email 2: Subject**".
+	sectionHeader = regexp.MustCompile(`(?i)^#*\s*\**\s*email\s*#?\s*(\d+)\s*\**\s*(?:[:.\-–—]\s*(.*?))?\s*\**\s*$`)
+
+	// fieldLine matches a labelled line such as "Subject: ..." or "**CTA:** ...".
+	fieldLine = regexp.MustCompile(`(?i)^[-*\s]*\**\s*(subject line|subject|preview text|preview|preheader|framework|cta|call to action|day delay|delay|body)\s*:\s*\**\s*(.*)$`)
+
+	// horizontalRule ends an email section.
+	horizontalRule = regexp.MustCompile(`^(?:-{3,}|\*{3,}|_{3,})$`)
+)
+
+// Parse extracts a journey from a StepExecution response: the summary table
+// (| Email # | Subject Line | Day Delay |) and the "Email N:" sections that
+// follow it. The table is authoritative for subject and delay; sections fill
+// in preview text, body, framework and CTA. It returns nil when the text
+// contains no emails.
+func Parse(text string) *Journey {
+	emails := make(map[int]*Email)
+	get := func(number int) *Email {
+		if emails[number] == nil {
+			emails[number] = &Email{Number: number, DayDelay: -1}
+		}
+		return emails[number]
+	}
+
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	var current *Email
+	var body []string
+	sectionSubject := make(map[int]string)
+
+	endSection := func() {
+		if current != nil {
+			current.Body = strings.TrimSpace(strings.Join(body, "\n"))
+		}
+		current = nil
+		body = nil
+	}
+
+	for _, raw := range lines {
+		line := strings.TrimSpace(raw)
+
+		if row, ok := ParseRow(line); ok {
+			endSection()
+			email := get(row.Number)
+			email.Subject = row.Subject
+			email.DayDelay = row.DayDelay
+			if row.Framework != "" {
+				email.Framework = row.Framework
+			}
+			continue
+		}
+
+		if m := sectionHeader.FindStringSubmatch(line); m != nil {
+			endSection()
+			number, _ := strconv.Atoi(m[1])
+			current = get(number)
+			sectionSubject[number] = cleanValue(m[2])
+			continue
+		}
+
+		if current == nil {
+			continue
+		}
+		if horizontalRule.MatchString(line) || strings.HasPrefix(line, "#") {
+			// A rule or any other heading closes the section
+			endSection()
+			continue
+		}
+
+		if m := fieldLine.FindStringSubmatch(line); m != nil {
+			value := cleanValue(m[2])
+			switch strings.ToLower(m[1]) {
+			case "subject", "subject line":
+				sectionSubject[current.Number] = value
+			case "preview", "preview text", "preheader":
+				current.PreviewText = value
+			case "framework":
+				current.Framework = value
+			case "cta", "call to action":
+				current.CTA = value
+			case "day delay", "delay":
+				if n := firstNumber.FindString(value); n != "" && current.DayDelay < 0 {
+					current.DayDelay, _ = strconv.Atoi(n)
+				}
+			case "body":
+				if value != "" {
+					body = append(body, value)
+				}
+			}
+			continue
+		}
+		body = append(body, strings.TrimRight(raw, " \t"))
+	}
+	endSection()
+
+	if len(emails) == 0 {
+		return nil
+	}
+
+	j := &Journey{Emails: make([]Email, 0, len(emails))}
+	for number, email := range emails {
+		if email.Subject == "" {
+			email.Subject = sectionSubject[number]
+		}
+		if email.DayDelay < 0 {
+			email.DayDelay = 0
+		}
+		email.SendAfterHours = email.DayDelay * 24
+		j.Emails = append(j.Emails, *email)
+	}
+	j.SortEmails()
+	return j
+}
+
+// ParseRow parses one summary table row such as "| 1 | Subject line | 0 |"
+// or "| 1 | Subject line | 0 | AIDA |". Header and separator rows are rejected.
+func ParseRow(line string) (Email, bool) {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "|") {
+		return Email{}, false
+	}
+	cells := strings.Split(strings.Trim(line, "|"), "|")
+	if len(cells) < 3 {
+		return Email{}, false
+	}
+
+	numberCell := cleanValue(cells[0])
+	if !strings.HasPrefix(strings.ToLower(numberCell), "email") && !startsWithDigit(numberCell) {
+		return Email{}, false
+	}
+	number := firstNumber.FindString(numberCell)
+	delay := firstNumber.FindString(cells[2])
+	if number == "" || delay == "" {
+		return Email{}, false
+	}
+
+	n, _ := strconv.Atoi(number)
+	d, _ := strconv.Atoi(delay)
+	email := Email{Number: n, Subject: cleanValue(cells[1]), DayDelay: d, SendAfterHours: d * 24}
+	if len(cells) > 3 {
+		// Optional fourth column, used by skeleton previews
+		email.Framework = cleanValue(cells[3])
+	}
+	return email, true
+}
+
+// cleanValue trims whitespace, markdown emphasis and surrounding quotes.
+func cleanValue(s string) string {
+	s = strings.TrimSpace(s)
+	s = strings.Trim(s, "*_`")
+	s = strings.TrimSpace(s)
+	if len(s) >= 2 && (s[0] == '"' && s[len(s)-1] == '"') {
+		s = s[1 : len(s)-1]
+	}
+	return s
+}
+
+func startsWithDigit(s string) bool {
+	return s != "" && s[0] >= '0' && s[0] <= '9'
+}
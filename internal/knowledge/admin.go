@@ -0,0 +1,294 @@
+package knowledge
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned when updating or deleting an entry that does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrExists is returned when creating an entry whose key is already taken.
+	ErrExists = errors.New("already exists")
+	// ErrInvalidKey is returned for keys that are not lowercase snake_case.
+	ErrInvalidKey = errors.New("key must match [a-z0-9_]+")
+	// ErrInUse is returned when deleting an entry other entries still refer to.
+	ErrInUse = errors.New("still in use")
+)
+
+var keyPattern = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// writeMode selects create, update or delete semantics for an edit.
+type writeMode int
+
+const (
+	modeCreate writeMode = iota
+	modeUpdate
+	modeDelete
+)
+
+// Frameworks returns a copy of all frameworks keyed by key.
+func (kb *KnowledgeBase) Frameworks() map[string]*Framework {
+	return maps.Clone(kb.snapshot().Frameworks)
+}
+
+// SequenceTemplates returns a copy of all sequence templates keyed by key.
+func (kb *KnowledgeBase) SequenceTemplates() map[string]*SequenceTemplate {
+	return maps.Clone(kb.snapshot().SequenceTemplates)
+}
+
+// VerticalGuides returns a copy of all vertical guidance keyed by key.
+func (kb *KnowledgeBase) VerticalGuides() map[string]VerticalGuidance {
+	return maps.Clone(kb.snapshot().VerticalGuides)
+}
+
+// CreateFramework adds a framework and persists frameworks.json.
+func (kb *KnowledgeBase) CreateFramework(key string, fw *Framework) error {
+	return kb.editFrameworks(key, fw, modeCreate)
+}
+
+// UpdateFramework replaces a framework and persists frameworks.json.
+func (kb *KnowledgeBase) UpdateFramework(key string, fw *Framework) error {
+	return kb.editFrameworks(key, fw, modeUpdate)
+}
+
+// DeleteFramework removes a framework and persists frameworks.json.
+func (kb *KnowledgeBase) DeleteFramework(key string) error {
+	return kb.editFrameworks(key, nil, modeDelete)
+}
+
+// CreateSequenceTemplate adds a sequence template and persists sequence.json.
+func (kb *KnowledgeBase) CreateSequenceTemplate(key string, st *SequenceTemplate) error {
+	return kb.editSequences(key, st, modeCreate)
+}
+
+// UpdateSequenceTemplate replaces a sequence template and persists sequence.json.
+func (kb *KnowledgeBase) UpdateSequenceTemplate(key string, st *SequenceTemplate) error {
+	return kb.editSequences(key, st, modeUpdate)
+}
+
+// DeleteSequenceTemplate removes a sequence template and persists sequence.json.
+func (kb *KnowledgeBase) DeleteSequenceTemplate(key string) error {
+	return kb.editSequences(key, nil, modeDelete)
+}
+
+// CreateVerticalGuidance adds vertical guidance and persists verticals.json.
+func (kb *KnowledgeBase) CreateVerticalGuidance(key string, vg VerticalGuidance) error {
+	return kb.editVerticals(key, vg, modeCreate)
+}
+
+// UpdateVerticalGuidance replaces vertical guidance and persists verticals.json.
+func (kb *KnowledgeBase) UpdateVerticalGuidance(key string, vg VerticalGuidance) error {
+	return kb.editVerticals(key, vg, modeUpdate)
+}
+
+// DeleteVerticalGuidance removes vertical guidance and persists verticals.json.
+func (kb *KnowledgeBase) DeleteVerticalGuidance(key string) error {
+	return kb.editVerticals(key, VerticalGuidance{}, modeDelete)
+}
+
+func (kb *KnowledgeBase) editFrameworks(key string, fw *Framework, mode writeMode) error {
+	kb.mu.Lock()
+	defer kb.mu.Unlock()
+
+	cur := kb.snapshot()
+	frameworks, err := applyEdit(cur.Frameworks, key, fw, mode, (*Framework).Validate)
+	if err != nil {
+		return err
+	}
+	if mode == modeDelete {
+		if err := checkDelete(cur, key, frameworks, cur.SequenceTemplates, cur.VerticalGuides); err != nil {
+			return err
+		}
+	}
+	return kb.commit(kb.paths.Frameworks, frameworksFile{Frameworks: frameworks},
+		frameworks, cur.SequenceTemplates, cur.VerticalGuides)
+}
+
+func (kb *KnowledgeBase) editSequences(key string, st *SequenceTemplate, mode writeMode) error {
+	kb.mu.Lock()
+	defer kb.mu.Unlock()
+
+	cur := kb.snapshot()
+	sequences, err := applyEdit(cur.SequenceTemplates, key, st, mode, (*SequenceTemplate).Validate)
+	if err != nil {
+		return err
+	}
+	if mode == modeDelete {
+		if err := checkDelete(cur, key, cur.Frameworks, sequences, cur.VerticalGuides); err != nil {
+			return err
+		}
+	} else if missing := missingStarts(sequences, st); len(missing) > 0 {
+		errs := make([]error, len(missing))
+		for i, target := range missing {
+			errs[i] = &FieldError{Field: "sequences." + key + ".branching_logic", Message: fmt.Sprintf("starts unknown sequence %q", target)}
+		}
+		return &ValidationError{Errors: errs}
+	}
+	return kb.commit(kb.paths.Sequences, sequencesFile{Sequences: sequences},
+		cur.Frameworks, sequences, cur.VerticalGuides)
+}
+
+func (kb *KnowledgeBase) editVerticals(key string, vg VerticalGuidance, mode writeMode) error {
+	kb.mu.Lock()
+	defer kb.mu.Unlock()
+
+	cur := kb.snapshot()
+	verticals, err := applyEdit(cur.VerticalGuides, key, vg, mode, VerticalGuidance.Validate)
+	if err != nil {
+		return err
+	}
+	if mode == modeDelete {
+		if err := checkDelete(cur, key, cur.Frameworks, cur.SequenceTemplates, verticals); err != nil {
+			return err
+		}
+	}
+	return kb.commit(kb.paths.Verticals, verticalsFile{Verticals: verticals},
+		cur.Frameworks, cur.SequenceTemplates, verticals)
+}
+
+// applyEdit returns a copy of entries with the edit applied, after checking
+// the key and validating the new value against the loader's schema.
+func applyEdit[T any](entries map[string]T, key string, value T, mode writeMode, validate func(T, string) []error) (map[string]T, error) {
+	if !keyPattern.MatchString(key) {
+		return nil, ErrInvalidKey
+	}
+
+	_, exists := entries[key]
+	switch {
+	case mode == modeCreate && exists:
+		return nil, fmt.Errorf("%q %w", key, ErrExists)
+	case mode != modeCreate && !exists:
+		return nil, fmt.Errorf("%q %w", key, ErrNotFound)
+	}
+
+	out := maps.Clone(entries)
+	if mode == modeDelete {
+		delete(out, key)
+		return out, nil
+	}
+	if errs := validate(value, key); len(errs) > 0 {
+		return nil, &ValidationError{Errors: errs}
+	}
+	out[key] = value
+	return out, nil
+}
+
+// InUseError lists the entries whose references a rejected delete would
+// break. It wraps ErrInUse.
+type InUseError struct {
+	Key       string
+	Sequences []string // keys of sequences referring to Key
+	Verticals []string // keys of verticals referring to Key
+}
+
+func (e *InUseError) Error() string {
+	var users []string
+	if len(e.Sequences) > 0 {
+		users = append(users, "sequences "+strings.Join(e.Sequences, ", "))
+	}
+	if len(e.Verticals) > 0 {
+		users = append(users, "verticals "+strings.Join(e.Verticals, ", "))
+	}
+	return fmt.Sprintf("%q is %v: referenced by %s", e.Key, ErrInUse, strings.Join(users, " and "))
+}
+
+func (e *InUseError) Unwrap() error { return ErrInUse }
+
+// checkDelete rejects the deletion of key if a framework, vertical or
+// start target reference that resolves in cur would no longer resolve once
+// the edited collections are in place; kblint reports such references as
+// errors.
+func checkDelete(cur *snapshot, key string, frameworks map[string]*Framework, sequences map[string]*SequenceTemplate, verticals map[string]VerticalGuidance) error {
+	after, err := newSnapshot(frameworks, sequences, verticals)
+	if err != nil {
+		return err
+	}
+	lost := func(framework string) bool {
+		return cur.exactFramework(framework) != nil && after.exactFramework(framework) == nil
+	}
+
+	inUse := &InUseError{Key: key}
+	for _, seqKey := range sortedKeys(after.SequenceTemplates) {
+		st := after.SequenceTemplates[seqKey]
+		_, hadVertical := cur.ResolveVertical(st.Vertical)
+		_, hasVertical := after.ResolveVertical(st.Vertical)
+		lostStart := len(missingStarts(after.SequenceTemplates, st)) > len(missingStarts(cur.SequenceTemplates, st))
+		if slices.ContainsFunc(st.Frameworks, lost) || hadVertical && !hasVertical || lostStart {
+			inUse.Sequences = append(inUse.Sequences, seqKey)
+		}
+	}
+	for _, vertKey := range sortedKeys(after.VerticalGuides) {
+		if slices.ContainsFunc(after.VerticalGuides[vertKey].Frameworks, lost) {
+			inUse.Verticals = append(inUse.Verticals, vertKey)
+		}
+	}
+	if len(inUse.Sequences) == 0 && len(inUse.Verticals) == 0 {
+		return nil
+	}
+	return inUse
+}
+
+// ValidationError wraps the schema violations of a rejected write.
+type ValidationError struct {
+	Errors []error
+}
+
+func (e *ValidationError) Error() string {
+	return errors.Join(e.Errors...).Error()
+}
+
+// commit persists file to path and swaps in a snapshot built from the
+// edited collections. Callers must hold kb.mu.
+func (kb *KnowledgeBase) commit(path string, file any, frameworks map[string]*Framework, sequences map[string]*SequenceTemplate, verticals map[string]VerticalGuidance) error {
+	snap, err := newSnapshot(frameworks, sequences, verticals)
+	if err != nil {
+		return err
+	}
+	if err := writeJSONFile(path, file); err != nil {
+		return err
+	}
+	// Stamp after writing so the watcher does not reload our own change
+	kb.swap(snap, kb.stampFiles(), time.Now())
+	return nil
+}
+
+// writeJSONFile writes v as indented JSON via a temp file and rename, so
+// readers (including the watcher) never see a partially written file.
+func writeJSONFile(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", path, err)
+	}
+	data = append(data, '\n')
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package knowledge
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// copyKnowledge copies the shipped knowledge files into a temp dir.
+func copyKnowledge(t *testing.T) Paths {
+	t.Helper()
+	dir := t.TempDir()
+	for _, name := range []string{"frameworks.json", "sequence.json", "verticals.json"} {
+		data, err := os.ReadFile(filepath.Join("..", "..", "data", "knowledge", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return DefaultPaths(dir)
+}
+
+func testSequence(branching string) *SequenceTemplate {
+	return &SequenceTemplate{
+		Outcome: "Win-back", Vertical: "DTC", Duration: "7 days", TouchPoints: 2, CadenceString: "Every 7 days",
+		Frameworks: []string{"AIDA"}, KeyMessages: []string{"We miss you", "Last chance"}, BranchingLogic: branching,
+	}
+}
+
+func TestStartTargets(t *testing.T) {
+	paths := copyKnowledge(t)
+	kb, err := NewKnowledgeBase(paths.Frameworks, paths.Sequences, paths.Verticals)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var valErr *ValidationError
+	err = kb.CreateSequenceTemplate("win_back", testSequence("IF no_engagement_for 7 days THEN start re_engagement"))
+	if !errors.As(err, &valErr) || !strings.Contains(err.Error(), `starts unknown sequence "re_engagement"`) {
+		t.Fatalf("create with unknown start target: err = %v, want a validation error", err)
+	}
+
+	if err := kb.CreateSequenceTemplate("win_back", testSequence("IF clicked THEN exit")); err != nil {
+		t.Fatal(err)
+	}
+	st := *kb.SequenceTemplates()["first_purchase_dtc"]
+	st.BranchingLogic = "IF clicked THEN exit; IF no_engagement_for 7 days THEN start win_back"
+	if err := kb.UpdateSequenceTemplate("first_purchase_dtc", &st); err != nil {
+		t.Fatalf("update with a known start target: %v", err)
+	}
+	if issues := Lint(paths); hasError(issues) {
+		t.Errorf("lint after valid edits: %v", issues)
+	}
+
+	var inUse *InUseError
+	err = kb.DeleteSequenceTemplate("win_back")
+	if !errors.As(err, &inUse) || len(inUse.Sequences) != 1 || inUse.Sequences[0] != "first_purchase_dtc" {
+		t.Fatalf("delete of a start target: err = %v, want it in use by first_purchase_dtc", err)
+	}
+}
+
+func TestLintStartTargets(t *testing.T) {
+	paths := copyKnowledge(t)
+	data, err := os.ReadFile(paths.Sequences)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data = []byte(strings.Replace(string(data), `"branching_logic": "IF clicked THEN exit"`,
+		`"branching_logic": "IF clicked THEN exit; IF purchased THEN start vip_nurture"`, 1))
+	if err := os.WriteFile(paths.Sequences, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var found bool
+	for _, issue := range Lint(paths) {
+		if issue.Severity == SeverityError {
+			if issue.Field != "sequences.first_purchase_dtc.branching_logic" || !strings.Contains(issue.Message, `"vip_nurture"`) {
+				t.Errorf("unexpected issue %s", issue)
+			}
+			found = true
+		}
+	}
+	if !found {
+		t.Error("lint did not report the unknown start target")
+	}
+}
+
+func TestLintCounts(t *testing.T) {
+	paths := copyKnowledge(t)
+	if issues := Lint(paths); hasError(issues) {
+		t.Fatalf("shipped knowledge has errors: %v", issues)
+	}
+
+	data, err := os.ReadFile(paths.Sequences)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edited := strings.NewReplacer(
+		`"Limited Offer",`, `"Limited Offer"`,
+		`        "Offer ending reminder"`+"\n", "",
+		`"cadence": "Every 5-6 days"`, `"cadence": "Every 3-4 days"`,
+	).Replace(string(data))
+	if err := os.WriteFile(paths.Sequences, []byte(edited), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]bool{
+		"sequences.first_purchase_dtc.key_messages":    false,
+		"sequences.supplement_onboarding.touch_points": false,
+	}
+	for _, issue := range Lint(paths) {
+		if issue.Severity != SeverityError {
+			continue
+		}
+		if _, ok := want[issue.Field]; !ok {
+			t.Errorf("unexpected issue %s", issue)
+		}
+		want[issue.Field] = true
+	}
+	for field, found := range want {
+		if !found {
+			t.Errorf("lint did not report %s as an error", field)
+		}
+	}
+}
+
+func hasError(issues []Issue) bool {
+	for _, issue := range issues {
+		if issue.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,243 @@
+package knowledge
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// span is a duration range such as "7-14 days"; min equals max for "21 days".
+type span struct {
+	min, max time.Duration
+}
+
+var (
+	spanPattern  = regexp.MustCompile(`^(?:(\d+)(?:\s*-\s*(\d+))?\s*)?([a-z]+)$`)
+	numberOnly   = regexp.MustCompile(`^\d+$`)
+	spanUnitSize = map[string]time.Duration{
+		"hour": time.Hour, "hours": time.Hour, "hr": time.Hour, "hrs": time.Hour,
+		"day": 24 * time.Hour, "days": 24 * time.Hour,
+		"week": 7 * 24 * time.Hour, "weeks": 7 * 24 * time.Hour,
+	}
+)
+
+// parseSpan parses "48 hours", "21 days", "7-14 days" or a bare unit such as "day".
+func parseSpan(s string) (span, bool) {
+	m := spanPattern.FindStringSubmatch(strings.ToLower(strings.TrimSpace(s)))
+	if m == nil {
+		return span{}, false
+	}
+	unit, ok := spanUnitSize[m[3]]
+	if !ok {
+		return span{}, false
+	}
+	lo := 1
+	if m[1] != "" {
+		lo, _ = strconv.Atoi(m[1])
+	}
+	hi := lo
+	if m[2] != "" {
+		hi, _ = strconv.Atoi(m[2])
+	}
+	if hi < lo {
+		return span{}, false
+	}
+	return span{min: time.Duration(lo) * unit, max: time.Duration(hi) * unit}, true
+}
+
+// Cadence is a parsed SequenceTemplate.CadenceString: either a repeating gap
+// such as "Every 2-3 days" or an explicit schedule of sends.
+type Cadence struct {
+	MinGap   time.Duration   // shortest gap between sends, for "Every ..." cadences
+	MaxGap   time.Duration   // longest gap between sends, for "Every ..." cadences
+	Schedule []time.Duration // send times after the trigger, for listed schedules
+}
+
+// ParseCadence understands the two cadence styles used in sequence.json:
+// "Every 2-3 days" (also "Daily" and "Weekly") and schedules such as
+// "1 hour, 12 hours, 24 hours" or "Sparse: 15, 30, 45 days", where bare
+// numbers take the next unit given.
+func ParseCadence(s string) (Cadence, error) {
+	text := strings.ToLower(strings.TrimSpace(s))
+	if _, rest, found := strings.Cut(text, ":"); found {
+		text = strings.TrimSpace(rest)
+	}
+	switch text {
+	case "daily":
+		text = "every day"
+	case "weekly":
+		text = "every week"
+	case "every other day":
+		text = "every 2 days"
+	}
+
+	if rest, found := strings.CutPrefix(text, "every "); found {
+		interval, ok := parseSpan(rest)
+		if !ok {
+			return Cadence{}, fmt.Errorf("cannot parse cadence %q", s)
+		}
+		return Cadence{MinGap: interval.min, MaxGap: interval.max}, nil
+	}
+
+	parts := strings.Split(text, ",")
+	schedule := make([]time.Duration, len(parts))
+	pending := 0 // bare numbers waiting for a unit
+	for i, part := range parts {
+		part = strings.TrimSpace(part)
+		if numberOnly.MatchString(part) {
+			pending++
+			continue
+		}
+		sp, ok := parseSpan(part)
+		if !ok || sp.min != sp.max {
+			return Cadence{}, fmt.Errorf("cannot parse cadence %q", s)
+		}
+		schedule[i] = sp.min
+		unit := spanUnitSize[spanPattern.FindStringSubmatch(part)[3]]
+		for j := i - pending; j < i; j++ {
+			n, _ := strconv.Atoi(strings.TrimSpace(parts[j]))
+			schedule[j] = time.Duration(n) * unit
+		}
+		pending = 0
+	}
+	if pending > 0 {
+		return Cadence{}, fmt.Errorf("cannot parse cadence %q: numbers without a unit", s)
+	}
+	for i := 1; i < len(schedule); i++ {
+		if schedule[i] < schedule[i-1] {
+			return Cadence{}, fmt.Errorf("cannot parse cadence %q: sends out of order", s)
+		}
+	}
+	return Cadence{Schedule: schedule}, nil
+}
+
+// Offsets returns the send time of each of count emails, measured from the
+// start of the journey. A repeating gap alternates the shortest and longest
+// gap from 0 (0, 2d, 5d, 7d, ... for "Every 2-3 days"). A schedule that
+// does not start at 0 lists the sends after an immediate first email, so the
+// journey always starts on day 0; extra entries are dropped and the last gap
+// repeats for any emails still missing. The result is the same for the same
+// input.
+func (c Cadence) Offsets(count int) []time.Duration {
+	if count <= 0 {
+		return nil
+	}
+	offsets := make([]time.Duration, count)
+
+	if c.Schedule == nil {
+		short, long := max(c.MinGap, time.Hour), max(c.MaxGap, time.Hour)
+		for i := 1; i < count; i++ {
+			gap := short
+			if i%2 == 0 {
+				gap = long
+			}
+			offsets[i] = offsets[i-1] + gap
+		}
+		return offsets
+	}
+
+	schedule := c.Schedule
+	if schedule[0] != 0 {
+		schedule = append([]time.Duration{0}, schedule...)
+	}
+	gap := defaultGapDays * 24 * time.Hour
+	if n := len(schedule); n > 1 {
+		gap = max(schedule[n-1]-schedule[n-2], time.Hour)
+	}
+	for i := range offsets {
+		if i < len(schedule) {
+			offsets[i] = schedule[i]
+		} else {
+			offsets[i] = offsets[i-1] + gap
+		}
+	}
+	return offsets
+}
+
+// formatSpan renders a duration in whole days, or hours below a day.
+func formatSpan(d time.Duration) string {
+	if d%(24*time.Hour) == 0 {
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	}
+	return fmt.Sprintf("%dh", d/time.Hour)
+}
+
+// defaultGapDays spaces emails when a template has no parseable cadence.
+const defaultGapDays = 2
+
+// SendOffsets spreads count emails over the template's cadence and returns
+// each email's send time from the start of the journey (see Cadence.Offsets).
+// A cadence that cannot be parsed falls back to one email every
+// defaultGapDays days.
+func (st *SequenceTemplate) SendOffsets(count int) []time.Duration {
+	c, err := ParseCadence(st.CadenceString)
+	if err != nil {
+		c = Cadence{MinGap: defaultGapDays * 24 * time.Hour, MaxGap: defaultGapDays * 24 * time.Hour}
+	}
+	return c.Offsets(count)
+}
+
+// DayDelays is SendOffsets rounded down to whole days, so a send one hour
+// after the trigger is on day 0.
+func (st *SequenceTemplate) DayDelays(count int) []int {
+	offsets := st.SendOffsets(count)
+	delays := make([]int, count)
+	for i, offset := range offsets {
+		delays[i] = int(offset / (24 * time.Hour))
+	}
+	return delays
+}
+
+// CheckDelays compares a journey's day delays (one per email, in order)
+// with the template's cadence and duration and returns a warning for each
+// place they disagree. It does not change the delays.
+func (st *SequenceTemplate) CheckDelays(delays []int) []string {
+	if len(delays) == 0 {
+		return nil
+	}
+	var warnings []string
+	const day = 24 * time.Hour
+
+	last := delays[len(delays)-1]
+	if duration, ok := parseSpan(st.Duration); ok {
+		minDays, maxDays := int(duration.min/day), int((duration.max+day-1)/day)
+		switch {
+		case last > maxDays:
+			warnings = append(warnings, fmt.Sprintf("journey runs %d days, longer than the template's %q", last, st.Duration))
+		case last*2 < minDays && len(delays) > 1:
+			// Templates rarely fill their whole duration, so only flag real compression
+			warnings = append(warnings, fmt.Sprintf("journey runs %d days, shorter than the template's %q", last, st.Duration))
+		}
+	}
+
+	sched, err := ParseCadence(st.CadenceString)
+	switch {
+	case err != nil:
+		return warnings
+	case sched.Schedule != nil:
+		// An explicit schedule: compare each email with its scheduled day
+		expected := st.DayDelays(len(delays))
+		for i := 1; i < len(delays); i++ {
+			diff := delays[i] - expected[i]
+			if diff < 0 {
+				diff = -diff
+			}
+			if diff >= 1 && diff*2 > expected[i] {
+				warnings = append(warnings, fmt.Sprintf("email %d is on day %d; the %q cadence schedules it on day %d",
+					i+1, delays[i], st.CadenceString, expected[i]))
+			}
+		}
+	default:
+		minGap, maxGap := int(sched.MinGap/day), int((sched.MaxGap+day-1)/day)
+		for i := 1; i < len(delays); i++ {
+			gap := delays[i] - delays[i-1]
+			if gap < minGap || gap > maxGap {
+				warnings = append(warnings, fmt.Sprintf("email %d comes %d day(s) after email %d; the cadence is %q",
+					i+1, gap, i, st.CadenceString))
+			}
+		}
+	}
+	return warnings
+}
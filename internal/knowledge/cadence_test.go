@@ -0,0 +1,96 @@
+package knowledge
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+const day = 24 * time.Hour
+
+func TestParseCadence(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Cadence
+	}{
+		{"Every 48 hours", Cadence{MinGap: 48 * time.Hour, MaxGap: 48 * time.Hour}},
+		{"Every 2-3 days", Cadence{MinGap: 2 * day, MaxGap: 3 * day}},
+		{"every 1 - 2 weeks", Cadence{MinGap: 7 * day, MaxGap: 14 * day}},
+		{"Daily", Cadence{MinGap: day, MaxGap: day}},
+		{"Weekly", Cadence{MinGap: 7 * day, MaxGap: 7 * day}},
+		{"Every other day", Cadence{MinGap: 2 * day, MaxGap: 2 * day}},
+		{"1 hour, 12 hours, 24 hours", Cadence{Schedule: []time.Duration{time.Hour, 12 * time.Hour, 24 * time.Hour}}},
+		{"Sparse: 15, 30, 45 days", Cadence{Schedule: []time.Duration{15 * day, 30 * day, 45 * day}}},
+		{"1 hour, 2 days, 1 week", Cadence{Schedule: []time.Duration{time.Hour, 2 * day, 7 * day}}},
+		{"4, 12 hours, 3, 5 days", Cadence{Schedule: []time.Duration{4 * time.Hour, 12 * time.Hour, 3 * day, 5 * day}}},
+	}
+	for _, tt := range tests {
+		got, err := ParseCadence(tt.in)
+		if err != nil {
+			t.Errorf("ParseCadence(%q): %v", tt.in, err)
+			continue
+		}
+		if got.MinGap != tt.want.MinGap || got.MaxGap != tt.want.MaxGap || !slices.Equal(got.Schedule, tt.want.Schedule) {
+			t.Errorf("ParseCadence(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseCadenceErrors(t *testing.T) {
+	for _, in := range []string{
+		"",
+		"As needed",
+		"Every few days",
+		"Every 3-2 days",
+		"Every 2 fortnights",
+		"15, 30, 45",
+		"1 day, 2-3 days",
+		"3 days, 1 day",
+		"2 days, 30",
+	} {
+		if c, err := ParseCadence(in); err == nil {
+			t.Errorf("ParseCadence(%q) = %+v, want an error", in, c)
+		}
+	}
+}
+
+func TestCadenceOffsets(t *testing.T) {
+	tests := []struct {
+		cadence string
+		count   int
+		want    []time.Duration
+	}{
+		{"Every 2-3 days", 4, []time.Duration{0, 2 * day, 5 * day, 7 * day}},
+		{"Daily", 3, []time.Duration{0, day, 2 * day}},
+		{"Daily", 0, nil},
+		// A schedule starting at 0 lists every send
+		{"0 hours, 1 hour, 24 hours", 3, []time.Duration{0, time.Hour, 24 * time.Hour}},
+		// Any other lists the sends after an immediate first email, and
+		// repeats its last gap when it runs out
+		{"1 hour, 12 hours, 24 hours", 5, []time.Duration{0, time.Hour, 12 * time.Hour, 24 * time.Hour, 36 * time.Hour}},
+		{"1 hour, 12 hours, 24 hours", 3, []time.Duration{0, time.Hour, 12 * time.Hour}},
+		{"Sparse: 15, 30, 45 days", 2, []time.Duration{0, 15 * day}},
+		{"Sparse: 15, 30, 45 days", 4, []time.Duration{0, 15 * day, 30 * day, 45 * day}},
+		{"0 hours, 2 days", 3, []time.Duration{0, 2 * day, 4 * day}},
+	}
+	for _, tt := range tests {
+		c, err := ParseCadence(tt.cadence)
+		if err != nil {
+			t.Fatalf("ParseCadence(%q): %v", tt.cadence, err)
+		}
+		if got := c.Offsets(tt.count); !slices.Equal(got, tt.want) {
+			t.Errorf("%q: Offsets(%d) = %v, want %v", tt.cadence, tt.count, got, tt.want)
+		}
+	}
+}
+
+func TestSendOffsetsFallback(t *testing.T) {
+	st := SequenceTemplate{CadenceString: "Whenever it feels right"}
+	want := []time.Duration{0, defaultGapDays * day, 2 * defaultGapDays * day}
+	if got := st.SendOffsets(3); !slices.Equal(got, want) {
+		t.Errorf("SendOffsets = %v, want %v", got, want)
+	}
+	if got := st.DayDelays(3); !slices.Equal(got, []int{0, defaultGapDays, 2 * defaultGapDays}) {
+		t.Errorf("DayDelays = %v", got)
+	}
+}
@@ -1,23 +1,30 @@
 package knowledge
 
 import (
-	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
-	"sync"
+
+	"JourneyBuilder/internal/branching"
+
+	lru "github.com/hashicorp/golang-lru/v2"
 )
 
+// lookupCacheSize bounds the number of cached lookups. Outcome queries are
+// free text, so the cache must not grow without limit.
+const lookupCacheSize = 256
+
 // Framework represents a copywriting framework
 type Framework struct {
 	Name          string   `json:"name"`           // AIDA, PAS, FAB, etc.
-	Acronym       string   `json:"acronym"`        // "AIDA"
-	Components    []string `json:"components"`     // ["Attention", "Interest", "Desire", "Action"]
+	Acronym       string   `json:"acronym"`        // "Attention, Interest, Desire, Action"
+	Components    []string `json:"components"`     // ["Attention: Grab focus", "Interest: Provide info", ...]
 	BestFor       []string `json:"best_for"`       // ["TOFU", "story-driven content"]
 	EmotionalTone string   `json:"emotional_tone"` // "positive", "urgent", "transformational"
 	FunnelStage   string   `json:"funnel_stage"`   // TOFU, MOFU, BOFU
 	Example       string   `json:"example"`        // Real example
 	Criticisms    string   `json:"criticisms"`     // Limitations
-	Score         float64  `json:"score"`          // Relevance score (optional, used by FrameworkLookup)
+	Score         float64  `json:"score"`          // Relevance score (optional)
 }
 
 // SequenceTemplate represents a pre-built outcome→sequence mapping
@@ -30,129 +37,266 @@ type SequenceTemplate struct {
 	CadenceString  string   `json:"cadence"`    // "Every 2-3 days"
 	Frameworks     []string `json:"frameworks"` // Which frameworks to apply
 	KeyMessages    []string `json:"key_messages"`
-	BranchingLogic string   `json:"branching_logic"` // If/Then logic
+	BranchingLogic string   `json:"branching_logic"` // rules in the branching package's DSL
+	// BranchingNotes keeps branching ideas the DSL cannot express yet, as
+	// the strategist wrote them. They guide generation but are not rules.
+	BranchingNotes string `json:"branching_notes,omitempty"`
+}
+
+// Branches parses BranchingLogic. Loaded templates have already passed
+// Validate, so an error here only comes from a template built in code; such
+// a template has no branches.
+func (st *SequenceTemplate) Branches() []branching.Rule {
+	rules, err := branching.Parse(st.BranchingLogic)
+	if err != nil {
+		return nil
+	}
+	return rules
 }
 
-// KnowledgeBase holds all compressed training data
-type KnowledgeBase struct {
-	Frameworks        map[string]*Framework        `json:"frameworks"`
-	SequenceTemplates map[string]*SequenceTemplate `json:"sequences"`
-	VerticalGuides    map[string]VerticalGuidance  `json:"verticals"`
-	cache             *sync.Map                    // LRU cache for frequent lookups
+// snapshot is one immutable, validated version of the knowledge files.
+// Each snapshot owns its lookup cache, so swapping in a new snapshot also
+// invalidates every cached lookup.
+type snapshot struct {
+	Frameworks        map[string]*Framework
+	SequenceTemplates map[string]*SequenceTemplate
+	VerticalGuides    map[string]VerticalGuidance
+	cache             *lru.Cache[string, any] // LRU cache for frequent (and fuzzy) lookups
 }
 
 type VerticalGuidance struct {
 	VerticalName         string   `json:"vertical_name"`
+	Aliases              []string `json:"aliases,omitempty"` // other names detected for this vertical, e.g. "ecommerce"
 	Characteristics      []string `json:"characteristics"`
 	KeyPrinciples        []string `json:"key_principles"`
-	CommonOutcomes       []string `json:"common_outcomes"`
+	CommonOutcomes       []string `json:"common_outcomes,omitempty"`
 	UniqueConsiderations string   `json:"unique_considerations"`
+	Frameworks           []string `json:"frameworks,omitempty"` // recommended frameworks for this vertical
 }
 
-// NewKnowledgeBase loads and initializes the KB from JSON
-func NewKnowledgeBase(frameworksPath, sequencesPath, verticalsPath string) (*KnowledgeBase, error) {
-	kb := &KnowledgeBase{
-		Frameworks:        make(map[string]*Framework),
-		SequenceTemplates: make(map[string]*SequenceTemplate),
-		VerticalGuides:    make(map[string]VerticalGuidance),
-		cache:             &sync.Map{},
-	}
+// fallbackVertical is used for sequence lookups when nothing matches the requested vertical.
+const fallbackVertical = "dtc"
+
+// loadSnapshot reads, decodes and validates all three knowledge files.
+// Each path is read from disk, falling back to the embedded copy of the same
+// file name. Files are decoded strictly: unknown fields, wrong types and
+// missing required fields fail with an error naming file and field.
+func loadSnapshot(paths Paths) (*snapshot, error) {
+	frameworksPath, sequencesPath, verticalsPath := paths.Frameworks, paths.Sequences, paths.Verticals
 
 	// Load frameworks
-	if err := loadJSON(frameworksPath, &kb.Frameworks); err != nil {
+	var frameworks frameworksFile
+	if err := loadJSON(frameworksPath, &frameworks); err != nil {
+		return nil, fmt.Errorf("failed to load frameworks: %w", err)
+	}
+	if err := validateFile(frameworksPath, "frameworks", frameworks.Frameworks, (*Framework).Validate); err != nil {
 		return nil, fmt.Errorf("failed to load frameworks: %w", err)
 	}
 
 	// Load sequences
-	if err := loadJSON(sequencesPath, &kb.SequenceTemplates); err != nil {
+	var sequences sequencesFile
+	if err := loadJSON(sequencesPath, &sequences); err != nil {
+		return nil, fmt.Errorf("failed to load sequences: %w", err)
+	}
+	if err := validateFile(sequencesPath, "sequences", sequences.Sequences, (*SequenceTemplate).Validate); err != nil {
 		return nil, fmt.Errorf("failed to load sequences: %w", err)
 	}
 
 	// Load verticals
-	if err := loadJSON(verticalsPath, &kb.VerticalGuides); err != nil {
+	var verticals verticalsFile
+	if err := loadJSON(verticalsPath, &verticals); err != nil {
+		return nil, fmt.Errorf("failed to load verticals: %w", err)
+	}
+	if err := validateFile(verticalsPath, "verticals", verticals.Verticals, VerticalGuidance.Validate); err != nil {
 		return nil, fmt.Errorf("failed to load verticals: %w", err)
 	}
 
-	return kb, nil
+	return newSnapshot(frameworks.Frameworks, sequences.Sequences, verticals.Verticals)
 }
 
-// GetFramework retrieves a copywriting framework with caching
-func (kb *KnowledgeBase) GetFramework(name string) *Framework {
-	cacheKey := fmt.Sprintf("fw:%s", name)
+// newSnapshot builds a snapshot with normalized keys and an empty cache.
+func newSnapshot(frameworks map[string]*Framework, sequences map[string]*SequenceTemplate, verticals map[string]VerticalGuidance) (*snapshot, error) {
+	cache, err := lru.New[string, any](lookupCacheSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create lookup cache: %w", err)
+	}
+	return &snapshot{
+		Frameworks:        normalizeKeys(frameworks),
+		SequenceTemplates: normalizeKeys(sequences),
+		VerticalGuides:    normalizeKeys(verticals),
+		cache:             cache,
+	}, nil
+}
 
-	if cached, found := kb.cache.Load(cacheKey); found {
+// GetFramework retrieves a copywriting framework by key, name or acronym,
+// falling back to fuzzy matching. Results are cached.
+func (s *snapshot) GetFramework(name string) *Framework {
+	key := normalizeKey(name)
+	cacheKey := "fw:" + key
+
+	if cached, found := s.cache.Get(cacheKey); found {
 		return cached.(*Framework)
 	}
 
-	framework := kb.Frameworks[strings.ToLower(name)]
+	framework := s.Frameworks[key]
+	if framework == nil {
+		framework = s.findFramework(key)
+	}
 	if framework != nil {
-		kb.cache.Store(cacheKey, framework)
+		s.cache.Add(cacheKey, framework)
 	}
 	return framework
 }
 
-// GetSequenceTemplate retrieves a pre-built sequence template
-func (kb *KnowledgeBase) GetSequenceTemplate(outcome, vertical string) *SequenceTemplate {
-	cacheKey := fmt.Sprintf("seq:%s:%s", outcome, vertical)
+// GetSequenceTemplate retrieves the best pre-built sequence template for an
+// outcome and vertical. Lookup order:
+//  1. exact key "<outcome>_<vertical>" or "<outcome>"
+//  2. best fuzzy outcome match, preferring templates for the vertical
+//  3. best fuzzy outcome match among the generic DTC templates
+func (s *snapshot) GetSequenceTemplate(outcome, vertical string) *SequenceTemplate {
+	cacheKey := fmt.Sprintf("seq:%s:%s", normalizeKey(outcome), normalizeKey(vertical))
 
-	if cached, found := kb.cache.Load(cacheKey); found {
+	if cached, found := s.cache.Get(cacheKey); found {
 		return cached.(*SequenceTemplate)
 	}
 
-	key := fmt.Sprintf("%s_%s", strings.ToLower(outcome), strings.ToLower(vertical))
-	template := kb.SequenceTemplates[key]
+	template := s.findSequenceTemplate(outcome, vertical)
 	if template != nil {
-		kb.cache.Store(cacheKey, template)
+		s.cache.Add(cacheKey, template)
 	}
 	return template
 }
 
-// GetVerticalGuidance returns guidance for a specific vertical
-func (kb *KnowledgeBase) GetVerticalGuidance(vertical string) VerticalGuidance {
-	return kb.VerticalGuides[strings.ToLower(vertical)]
+// GetVerticalGuidance returns guidance for a specific vertical or one of its aliases.
+func (s *snapshot) GetVerticalGuidance(vertical string) VerticalGuidance {
+	key, ok := s.ResolveVertical(vertical)
+	if !ok {
+		return VerticalGuidance{}
+	}
+	return s.VerticalGuides[key]
+}
+
+// ResolveVertical maps a vertical name or alias (case- and space-insensitive)
+// to its VerticalGuides key.
+func (s *snapshot) ResolveVertical(vertical string) (string, bool) {
+	key := normalizeKey(vertical)
+	if key == "" {
+		return "", false
+	}
+	if _, ok := s.VerticalGuides[key]; ok {
+		return key, true
+	}
+	compact := strings.ReplaceAll(key, "_", "")
+	for guideKey, guide := range s.VerticalGuides {
+		if strings.ReplaceAll(guideKey, "_", "") == compact {
+			return guideKey, true
+		}
+		for _, alias := range guide.Aliases {
+			if strings.ReplaceAll(normalizeKey(alias), "_", "") == compact {
+				return guideKey, true
+			}
+		}
+	}
+	return "", false
+}
+
+// GetFrameworksForVertical returns the frameworks recommended for a vertical.
+func (s *snapshot) GetFrameworksForVertical(vertical string) []*Framework {
+	var frameworks []*Framework
+	for _, name := range s.GetVerticalGuidance(vertical).Frameworks {
+		if fw := s.GetFramework(name); fw != nil {
+			frameworks = append(frameworks, fw)
+		}
+	}
+	return frameworks
+}
+
+// ListFrameworks returns all frameworks ordered by key.
+func (s *snapshot) ListFrameworks() []*Framework {
+	keys := sortedKeys(s.Frameworks)
+	list := make([]*Framework, 0, len(keys))
+	for _, key := range keys {
+		list = append(list, s.Frameworks[key])
+	}
+	return list
+}
+
+// ListSequencesForVertical returns the templates for a vertical (or all
+// templates when vertical is empty), keyed by template key.
+func (s *snapshot) ListSequencesForVertical(vertical string) map[string]*SequenceTemplate {
+	want, _ := s.ResolveVertical(vertical)
+	sequences := make(map[string]*SequenceTemplate)
+	for key, template := range s.SequenceTemplates {
+		if vertical != "" {
+			if got, _ := s.ResolveVertical(template.Vertical); got != want || want == "" {
+				continue
+			}
+		}
+		sequences[key] = template
+	}
+	return sequences
 }
 
 // ExtractRelevantContext builds a minimal context string from KB
 // This is called by the instruction composer to inject only relevant knowledge
-func (kb *KnowledgeBase) ExtractRelevantContext(outcome, vertical, currentStep string) string {
+func (s *snapshot) ExtractRelevantContext(outcome, vertical, currentStep string) string {
+	return s.ExtractRelevantContextWithTouchPoints(outcome, vertical, currentStep, 0)
+}
+
+// ExtractRelevantContextWithTouchPoints is ExtractRelevantContext with the
+// template's touch points replaced by touchPoints when it is positive, so the
+// prompt does not ask for two different email counts.
+func (s *snapshot) ExtractRelevantContextWithTouchPoints(outcome, vertical, currentStep string, touchPoints int) string {
 	var sb strings.Builder
 
+	var template *SequenceTemplate
+	if outcome != "" {
+		template = s.GetSequenceTemplate(outcome, vertical)
+	}
+
 	// Add relevant frameworks (only at StepExecution when actually generating sequence)
-	recommendedFrameworks := kb.getFrameworksForStep(currentStep)
+	recommendedFrameworks := s.getFrameworksForStep(currentStep, template, vertical)
 	if len(recommendedFrameworks) > 0 {
 		sb.WriteString("## APPLICABLE COPYWRITING FRAMEWORKS\n\n")
-		for _, fw := range recommendedFrameworks {
-			if framework := kb.GetFramework(fw); framework != nil {
-				sb.WriteString(fmt.Sprintf("**%s (%s):** %s\n",
-					framework.Name,
-					framework.Acronym,
-					framework.BestFor[0]))
-				if framework.EmotionalTone != "" {
-					sb.WriteString(fmt.Sprintf("**Tone:** %s\n", framework.EmotionalTone))
-				}
-				if len(framework.Components) > 0 {
-					sb.WriteString(fmt.Sprintf("**Components:** %s\n", strings.Join(framework.Components, ", ")))
-				}
+		for _, framework := range recommendedFrameworks {
+			sb.WriteString(fmt.Sprintf("**%s (%s):** %s\n",
+				framework.Name,
+				framework.Acronym,
+				framework.BestFor[0]))
+			if framework.EmotionalTone != "" {
+				sb.WriteString(fmt.Sprintf("**Tone:** %s\n", framework.EmotionalTone))
+			}
+			if len(framework.Components) > 0 {
+				sb.WriteString(fmt.Sprintf("**Components:** %s\n", strings.Join(framework.Components, ", ")))
 			}
 		}
 		sb.WriteString("\n")
 	}
 
 	// Add sequence template if available
-	if outcome != "" && vertical != "" {
-		if template := kb.GetSequenceTemplate(outcome, vertical); template != nil {
-			sb.WriteString("\n## SEQUENCE TEMPLATE\n\n")
-			sb.WriteString(fmt.Sprintf("Outcome: %s\n", template.Outcome))
-			sb.WriteString(fmt.Sprintf("Duration: %s\n", template.Duration))
+	if template != nil {
+		sb.WriteString("\n## SEQUENCE TEMPLATE\n\n")
+		sb.WriteString(fmt.Sprintf("Outcome: %s (%s)\n", template.Outcome, template.Vertical))
+		sb.WriteString(fmt.Sprintf("Duration: %s\n", template.Duration))
+		if touchPoints > 0 && touchPoints != template.TouchPoints {
+			sb.WriteString(fmt.Sprintf("Touch Points: %d (set by the brief; spread the key messages across them)\n", touchPoints))
+		} else {
 			sb.WriteString(fmt.Sprintf("Touch Points: %d\n", template.TouchPoints))
-			sb.WriteString(fmt.Sprintf("Cadence: %s\n", template.CadenceString))
-			sb.WriteString(fmt.Sprintf("Key Messages: %s\n", strings.Join(template.KeyMessages, ", ")))
+		}
+		sb.WriteString(fmt.Sprintf("Cadence: %s\n", template.CadenceString))
+		sb.WriteString(fmt.Sprintf("Recommended Frameworks: %s\n", strings.Join(template.Frameworks, ", ")))
+		sb.WriteString(fmt.Sprintf("Key Messages: %s\n", strings.Join(template.KeyMessages, ", ")))
+		if template.BranchingLogic != "" {
+			sb.WriteString(fmt.Sprintf("Branching Logic: %s\n", template.BranchingLogic))
+		}
+		if template.BranchingNotes != "" {
+			sb.WriteString(fmt.Sprintf("Branching Notes: %s\n", template.BranchingNotes))
 		}
 	}
 
 	// Add vertical guidance if detected
 	if vertical != "" {
-		if guidance := kb.GetVerticalGuidance(vertical); guidance.VerticalName != "" {
+		if guidance := s.GetVerticalGuidance(vertical); guidance.VerticalName != "" {
 			sb.WriteString("\n## VERTICAL GUIDANCE\n\n")
 			sb.WriteString(fmt.Sprintf("Characteristics: %s\n", strings.Join(guidance.Characteristics, "; ")))
 			sb.WriteString(fmt.Sprintf("Key Principles: %s\n", strings.Join(guidance.KeyPrinciples, "; ")))
@@ -162,34 +306,66 @@ func (kb *KnowledgeBase) ExtractRelevantContext(outcome, vertical, currentStep s
 	return sb.String()
 }
 
-// getFrameworksForStep returns recommended frameworks for a workflow step
-func (kb *KnowledgeBase) getFrameworksForStep(step string) []string {
-	frameworkMap := map[string][]string{
-		"StepDiscovery":            {},             // No frameworks needed - just asking for USP/ICP
-		"StepValidation":           {},             // No frameworks needed - just validating understanding
-		"StepFrameworkApplication": {},             // No frameworks needed - just asking about Circles of Trust
-		"StepCircleConfirmation":   {},             // No frameworks needed - just confirming Circle
-		"StepGoalSetting":          {},             // No frameworks needed - just asking for outcome
-		"StepAnalysis":             {},             // No frameworks needed - just analyzing appropriateness
-		"StepExecution":            {"4Ps", "FAB"}, // Only inject frameworks when actually generating sequence
+// defaultExecutionFrameworks are used at StepExecution when neither the
+// sequence template nor the vertical recommends any frameworks.
+var defaultExecutionFrameworks = []string{"4Ps", "FAB"}
+
+// ExecutionFrameworks returns the frameworks to apply when generating a
+// sequence, in the order documented on getFrameworksForStep.
+func (s *snapshot) ExecutionFrameworks(template *SequenceTemplate, vertical string) []*Framework {
+	return s.getFrameworksForStep("StepExecution", template, vertical)
+}
+
+// getFrameworksForStep returns recommended frameworks for a workflow step.
+// Frameworks are only needed at StepExecution, when the sequence is actually
+// generated; earlier steps just gather and confirm information.
+func (s *snapshot) getFrameworksForStep(step string, template *SequenceTemplate, vertical string) []*Framework {
+	if step != "StepExecution" {
+		return nil
 	}
 
-	if frameworks, found := frameworkMap[step]; found {
-		return frameworks
+	// Prefer the template's frameworks, then the vertical's, to keep the prompt small
+	var names []string
+	if template != nil {
+		names = template.Frameworks
+	}
+	if len(names) == 0 {
+		names = s.GetVerticalGuidance(vertical).Frameworks
+	}
+	if len(names) == 0 {
+		names = defaultExecutionFrameworks
 	}
-	return []string{} // Default to empty - frameworks only needed at execution
+
+	var frameworks []*Framework
+	seen := make(map[*Framework]bool)
+	for _, name := range names {
+		if fw := s.GetFramework(name); fw != nil && !seen[fw] {
+			seen[fw] = true
+			frameworks = append(frameworks, fw)
+		}
+	}
+	return frameworks
 }
 
-func loadJSON(path string, target interface{}) error {
-	// In production, load from file system or embedded assets
-	data, err := readFileOrAsset(path)
-	if err != nil {
-		return err
+// normalizeKey lowercases a key and replaces spaces and dashes with underscores.
+func normalizeKey(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	return strings.NewReplacer(" ", "_", "-", "_").Replace(s)
+}
+
+func normalizeKeys[T any](entries map[string]T) map[string]T {
+	out := make(map[string]T, len(entries))
+	for key, value := range entries {
+		out[normalizeKey(key)] = value
 	}
-	return json.Unmarshal(data, target)
+	return out
 }
 
-func readFileOrAsset(path string) ([]byte, error) {
-	// Simplified: in production use embed.FS or ioutil.ReadFile
-	return []byte("{}"), nil
+func sortedKeys[T any](entries map[string]T) []string {
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
 }
@@ -0,0 +1,397 @@
+package knowledge
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"JourneyBuilder/internal/branching"
+)
+
+// Severity classifies a lint finding.
+type Severity string
+
+const (
+	// SeverityError marks content that is broken or will not load.
+	SeverityError Severity = "error"
+	// SeverityWarning marks content that loads but is probably inconsistent.
+	SeverityWarning Severity = "warning"
+)
+
+// Issue is a single problem found by Lint.
+type Issue struct {
+	Severity Severity
+	File     string
+	Line     int    // 0 when the line is unknown
+	Field    string // dotted JSON path, e.g. "sequences.cart_abandonment.frameworks"
+	Message  string
+}
+
+func (i Issue) String() string {
+	location := i.File
+	if i.Line > 0 {
+		location = fmt.Sprintf("%s:%d", i.File, i.Line)
+	}
+	if i.Field == "" {
+		return fmt.Sprintf("%s: %s: %s", location, i.Severity, i.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s: %s", location, i.Severity, i.Field, i.Message)
+}
+
+// lintFile is one knowledge file as seen by the linter.
+type lintFile struct {
+	path  string
+	raw   []byte
+	lines map[string]int // JSON path -> line of its key
+}
+
+// Lint checks the knowledge files on disk and returns every problem found,
+// ordered by file and line. Unlike loading, it keeps going after the first
+// failure so one run reports everything:
+//   - unreadable files, invalid JSON, unknown fields and wrong types
+//   - duplicate keys, including keys that only differ by case or separators
+//   - missing required fields
+//   - frameworks, verticals and start targets referenced by sequences or verticals that do not exist
+//   - touch_points that disagree with key_messages, the cadence or the duration
+//   - branching_notes not yet converted to rules, and durations that cannot be parsed (warnings)
+func Lint(paths Paths) []Issue {
+	var issues []Issue
+
+	fwFile, fwIssues := readLintFile(paths.Frameworks)
+	seqFile, seqIssues := readLintFile(paths.Sequences)
+	vertFile, vertIssues := readLintFile(paths.Verticals)
+	issues = append(issues, fwIssues...)
+	issues = append(issues, seqIssues...)
+	issues = append(issues, vertIssues...)
+
+	var frameworks frameworksFile
+	var sequences sequencesFile
+	var verticals verticalsFile
+	fwOK := fwFile != nil && decodeLintFile(fwFile, &frameworks, &issues)
+	seqOK := seqFile != nil && decodeLintFile(seqFile, &sequences, &issues)
+	vertOK := vertFile != nil && decodeLintFile(vertFile, &verticals, &issues)
+
+	if fwOK {
+		issues = append(issues, lintEntries(fwFile, "frameworks", frameworks.Frameworks, (*Framework).Validate)...)
+	}
+	if seqOK {
+		issues = append(issues, lintEntries(seqFile, "sequences", sequences.Sequences, (*SequenceTemplate).Validate)...)
+		for _, key := range sortedKeys(sequences.Sequences) {
+			if st := sequences.Sequences[key]; st != nil {
+				issues = append(issues, lintCounts(seqFile, key, st)...)
+				if st.BranchingNotes != "" {
+					issues = append(issues, seqFile.issue(SeverityWarning, "sequences."+key+".branching_notes",
+						fmt.Sprintf("%q is not converted to branching rules", st.BranchingNotes)))
+				}
+			}
+		}
+	}
+	if vertOK {
+		issues = append(issues, lintEntries(vertFile, "verticals", verticals.Verticals, VerticalGuidance.Validate)...)
+	}
+
+	// Cross-references need all three files
+	if fwOK && seqOK && vertOK {
+		snap, err := newSnapshot(frameworks.Frameworks, sequences.Sequences, verticals.Verticals)
+		if err != nil {
+			issues = append(issues, Issue{Severity: SeverityError, Message: err.Error()})
+		} else {
+			issues = append(issues, lintReferences(snap, seqFile, sequences.Sequences, vertFile, verticals.Verticals)...)
+		}
+	}
+
+	sort.SliceStable(issues, func(a, b int) bool {
+		if issues[a].File != issues[b].File {
+			return issues[a].File < issues[b].File
+		}
+		return issues[a].Line < issues[b].Line
+	})
+	return issues
+}
+
+// readLintFile reads a file from disk (lint never falls back to the
+// embedded copies) and scans it for duplicate keys.
+func readLintFile(path string) (*lintFile, []Issue) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, []Issue{{Severity: SeverityError, File: path, Message: err.Error()}}
+	}
+	lines, issues := scanKeys(path, raw)
+	return &lintFile{path: path, raw: raw, lines: lines}, issues
+}
+
+// decodeLintFile decodes f strictly into target, recording a failure as an issue.
+func decodeLintFile(f *lintFile, target interface{}, issues *[]Issue) bool {
+	dec := json.NewDecoder(bytes.NewReader(f.raw))
+	dec.DisallowUnknownFields()
+	err := dec.Decode(target)
+	if err == nil {
+		if _, tokErr := dec.Token(); tokErr != io.EOF {
+			err = errors.New("unexpected data after the top-level object")
+		}
+	}
+	if err != nil {
+		line, msg := decodeErrorDetail(f.raw, dec.InputOffset(), err)
+		*issues = append(*issues, Issue{Severity: SeverityError, File: f.path, Line: line, Message: msg})
+		return false
+	}
+	return true
+}
+
+// lintEntries reports missing sections, required fields and keys that
+// collide once normalized.
+func lintEntries[T any](f *lintFile, section string, entries map[string]T, validate func(T, string) []error) []Issue {
+	if entries == nil {
+		return []Issue{f.issue(SeverityError, section, "is required")}
+	}
+
+	var issues []Issue
+	byNormalized := make(map[string][]string)
+	for _, key := range sortedKeys(entries) {
+		byNormalized[normalizeKey(key)] = append(byNormalized[normalizeKey(key)], key)
+		for _, err := range validate(entries[key], key) {
+			var fieldErr *FieldError
+			if errors.As(err, &fieldErr) {
+				issues = append(issues, f.issue(SeverityError, fieldErr.Field, fieldErr.Message))
+				continue
+			}
+			issues = append(issues, f.issue(SeverityError, section+"."+key, err.Error()))
+		}
+	}
+	for _, normalized := range sortedKeys(byNormalized) {
+		if keys := byNormalized[normalized]; len(keys) > 1 {
+			issues = append(issues, f.issue(SeverityError, section+"."+keys[1],
+				fmt.Sprintf("keys %s all normalize to %q; only one will be loaded", strings.Join(keys, ", "), normalized)))
+		}
+	}
+	return issues
+}
+
+// lintReferences checks that sequences and verticals only name frameworks,
+// verticals and, in start rules, sequences that exist.
+func lintReferences(snap *snapshot, seqFile *lintFile, sequences map[string]*SequenceTemplate, vertFile *lintFile, verticals map[string]VerticalGuidance) []Issue {
+	var issues []Issue
+	for _, key := range sortedKeys(sequences) {
+		st := sequences[key]
+		if st == nil {
+			continue
+		}
+		prefix := "sequences." + key
+		for _, name := range st.Frameworks {
+			if msg, ok := checkFrameworkRef(snap, name); !ok {
+				issues = append(issues, seqFile.issue(SeverityError, prefix+".frameworks", msg))
+			}
+		}
+		if st.Vertical != "" {
+			if _, ok := snap.ResolveVertical(st.Vertical); !ok {
+				issues = append(issues, seqFile.issue(SeverityError, prefix+".vertical",
+					fmt.Sprintf("no vertical guidance matches %q", st.Vertical)))
+			}
+		}
+		for _, target := range missingStarts(snap.SequenceTemplates, st) {
+			issues = append(issues, seqFile.issue(SeverityError, prefix+".branching_logic",
+				fmt.Sprintf("starts unknown sequence %q", target)))
+		}
+	}
+	for _, key := range sortedKeys(verticals) {
+		for _, name := range verticals[key].Frameworks {
+			if msg, ok := checkFrameworkRef(snap, name); !ok {
+				issues = append(issues, vertFile.issue(SeverityError, "verticals."+key+".frameworks", msg))
+			}
+		}
+	}
+	return issues
+}
+
+// missingStarts returns the sequences st's rules start that are not in
+// sequences, which must be keyed by normalized key.
+func missingStarts(sequences map[string]*SequenceTemplate, st *SequenceTemplate) []string {
+	var missing []string
+	for _, r := range st.Branches() {
+		if r.Then.Type != branching.ActionStart {
+			continue
+		}
+		if _, ok := sequences[normalizeKey(r.Then.Sequence)]; !ok && !slices.Contains(missing, r.Then.Sequence) {
+			missing = append(missing, r.Then.Sequence)
+		}
+	}
+	return missing
+}
+
+// checkFrameworkRef reports whether name resolves to a framework exactly.
+// Fuzzy matches are not accepted, but are suggested in the message.
+func checkFrameworkRef(snap *snapshot, name string) (string, bool) {
+	if snap.exactFramework(name) != nil {
+		return "", true
+	}
+	if fw := snap.findFramework(name); fw != nil {
+		return fmt.Sprintf("unknown framework %q (did you mean %q?)", name, fw.Name), false
+	}
+	return fmt.Sprintf("unknown framework %q", name), false
+}
+
+// lintCounts reports touch_points that disagree with key_messages, the
+// cadence or the duration. These are errors: a generated journey takes its
+// email count, topics and schedule from these fields, so a mismatch produces
+// journeys that contradict their own template.
+func lintCounts(f *lintFile, key string, st *SequenceTemplate) []Issue {
+	prefix := "sequences." + key
+	var issues []Issue
+	if st.TouchPoints <= 0 {
+		return nil // reported by Validate
+	}
+
+	if len(st.KeyMessages) > 0 && len(st.KeyMessages) != st.TouchPoints {
+		issues = append(issues, f.issue(SeverityError, prefix+".key_messages",
+			fmt.Sprintf("has %d entries but touch_points is %d", len(st.KeyMessages), st.TouchPoints)))
+	}
+
+	duration, durationOK := parseSpan(st.Duration)
+	if st.Duration != "" && !durationOK {
+		issues = append(issues, f.issue(SeverityWarning, prefix+".duration",
+			fmt.Sprintf("cannot parse %q", st.Duration)))
+	}
+
+	sched, err := ParseCadence(st.CadenceString)
+	switch {
+	case st.CadenceString == "":
+		// reported by Validate
+	case err != nil:
+		issues = append(issues, f.issue(SeverityError, prefix+".cadence", err.Error()))
+	case sched.Schedule != nil:
+		// A schedule starting at 0 lists every send; any other lists the
+		// sends after an immediate first email (see Cadence.Offsets)
+		switch n := len(sched.Schedule); {
+		case sched.Schedule[0] == 0 && n != st.TouchPoints:
+			issues = append(issues, f.issue(SeverityError, prefix+".cadence",
+				fmt.Sprintf("lists %d sends but touch_points is %d", n, st.TouchPoints)))
+		case sched.Schedule[0] != 0 && n != st.TouchPoints-1:
+			issues = append(issues, f.issue(SeverityError, prefix+".cadence",
+				fmt.Sprintf("lists %d sends after the first email but touch_points is %d", n, st.TouchPoints)))
+		}
+		if last := sched.Schedule[len(sched.Schedule)-1]; durationOK && last > duration.max {
+			issues = append(issues, f.issue(SeverityError, prefix+".cadence",
+				fmt.Sprintf("last send at %s is after the %q duration", formatSpan(last), st.Duration)))
+		}
+	case durationOK:
+		gaps := time.Duration(st.TouchPoints - 1)
+		spanMin, spanMax := sched.MinGap*gaps, sched.MaxGap*gaps
+		if spanMax < duration.min || spanMin > duration.max {
+			issues = append(issues, f.issue(SeverityError, prefix+".touch_points",
+				fmt.Sprintf("%d sends %s span %s-%s, which does not fit the %q duration",
+					st.TouchPoints, strings.ToLower(st.CadenceString), formatSpan(spanMin), formatSpan(spanMax), st.Duration)))
+		}
+	}
+	return issues
+}
+
+// issue builds an Issue located at the closest known line for field.
+func (f *lintFile) issue(severity Severity, field, message string) Issue {
+	return Issue{Severity: severity, File: f.path, Line: f.lineFor(field), Field: field, Message: message}
+}
+
+// lineFor returns the line of field or of its nearest parent.
+func (f *lintFile) lineFor(field string) int {
+	for field != "" {
+		if line, ok := f.lines[field]; ok {
+			return line
+		}
+		cut := strings.LastIndexAny(field, ".[")
+		if cut < 0 {
+			break
+		}
+		field = field[:cut]
+	}
+	return 0
+}
+
+// scanKeys walks the JSON tokens of raw, recording the line of every object
+// key and reporting keys repeated within the same object. encoding/json
+// silently keeps the last duplicate, so they are invisible after decoding.
+func scanKeys(path string, raw []byte) (map[string]int, []Issue) {
+	type frame struct {
+		path      string
+		object    bool
+		expectKey bool
+		key       string
+		index     int
+		seen      map[string]int
+	}
+
+	lines := make(map[string]int)
+	var issues []Issue
+	var stack []*frame
+
+	// valueDone advances the enclosing container past one value
+	valueDone := func() {
+		if len(stack) == 0 {
+			return
+		}
+		top := stack[len(stack)-1]
+		if top.object {
+			top.expectKey = true
+		} else {
+			top.index++
+		}
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			// Syntax errors are reported by the strict decode
+			return lines, issues
+		}
+
+		var top *frame
+		childPath := ""
+		if len(stack) > 0 {
+			top = stack[len(stack)-1]
+			if top.object {
+				childPath = strings.TrimPrefix(top.path+"."+top.key, ".")
+			} else {
+				childPath = fmt.Sprintf("%s[%d]", top.path, top.index)
+			}
+		}
+
+		if top != nil && top.object && top.expectKey {
+			if tok == json.Delim('}') {
+				stack = stack[:len(stack)-1]
+				valueDone()
+				continue
+			}
+			key, _ := tok.(string)
+			keyPath := strings.TrimPrefix(top.path+"."+key, ".")
+			line := lineAt(raw, dec.InputOffset())
+			if first, dup := top.seen[key]; dup {
+				issues = append(issues, Issue{Severity: SeverityError, File: path, Line: line, Field: keyPath,
+					Message: fmt.Sprintf("duplicate key (first defined on line %d); only the last one is loaded", first)})
+			} else {
+				top.seen[key] = line
+				lines[keyPath] = line
+			}
+			top.key = key
+			top.expectKey = false
+			continue
+		}
+
+		switch tok {
+		case json.Delim('{'):
+			stack = append(stack, &frame{path: childPath, object: true, expectKey: true, seen: make(map[string]int)})
+		case json.Delim('['):
+			stack = append(stack, &frame{path: childPath})
+		case json.Delim('}'), json.Delim(']'):
+			stack = stack[:len(stack)-1]
+			valueDone()
+		default:
+			valueDone()
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package knowledge
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+
+	"JourneyBuilder/data"
+	"JourneyBuilder/internal/logger"
+)
+
+// frameworksFile is the on-disk layout of frameworks.json.
+type frameworksFile struct {
+	Frameworks map[string]*Framework `json:"frameworks"`
+}
+
+// sequencesFile is the on-disk layout of sequence.json.
+type sequencesFile struct {
+	Sequences map[string]*SequenceTemplate `json:"sequences"`
+}
+
+// verticalsFile is the on-disk layout of verticals.json.
+type verticalsFile struct {
+	Verticals map[string]VerticalGuidance `json:"verticals"`
+}
+
+// loadJSON reads path (falling back to the embedded copy) and decodes it
+// strictly into target. Errors name the file and, where possible, the field.
+func loadJSON(path string, target interface{}) error {
+	raw, err := readFileOrAsset(path)
+	if err != nil {
+		return err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(target); err != nil {
+		return describeDecodeError(path, raw, dec.InputOffset(), err)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return fmt.Errorf("%s: unexpected data after the top-level object", path)
+	}
+	return nil
+}
+
+// readFileOrAsset reads path from disk, or from the embedded data/knowledge
+// files when it does not exist on disk.
+func readFileOrAsset(filePath string) ([]byte, error) {
+	raw, err := os.ReadFile(filePath)
+	if err == nil {
+		return raw, nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%s: %w", filePath, err)
+	}
+
+	assetPath := path.Join("knowledge", filepath.Base(filePath))
+	raw, assetErr := data.Knowledge.ReadFile(assetPath)
+	if assetErr != nil {
+		return nil, fmt.Errorf("%s: not found on disk or in embedded assets: %w", filePath, err)
+	}
+	logger.Printf("ℹ️  %s not found on disk, using embedded %s", filePath, assetPath)
+	return raw, nil
+}
+
+// describeDecodeError turns a json decoding error into one that names the
+// file, the offending field and the line it was found on.
+func describeDecodeError(path string, raw []byte, offset int64, err error) error {
+	line, msg := decodeErrorDetail(raw, offset, err)
+	return fmt.Errorf("%s:%d: %s", path, line, msg)
+}
+
+// decodeErrorDetail returns the line a decoding error occurred on and a
+// description of it naming the offending field where possible.
+func decodeErrorDetail(raw []byte, offset int64, err error) (int, string) {
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+	switch {
+	case errors.As(err, &typeErr):
+		return lineAt(raw, typeErr.Offset), fmt.Sprintf("field %q: expected %s, got JSON %s",
+			typeErr.Field, typeErr.Type, typeErr.Value)
+	case errors.As(err, &syntaxErr):
+		return lineAt(raw, syntaxErr.Offset), fmt.Sprintf("invalid JSON: %v", syntaxErr)
+	default:
+		// Unknown fields are reported as plain errors; the decoder offset
+		// points just past the offending key.
+		return lineAt(raw, offset), err.Error()
+	}
+}
+
+// lineAt returns the 1-based line number of a byte offset.
+func lineAt(raw []byte, offset int64) int {
+	if offset > int64(len(raw)) {
+		offset = int64(len(raw))
+	}
+	return bytes.Count(raw[:offset], []byte("\n")) + 1
+}
@@ -0,0 +1,187 @@
+package knowledge
+
+import (
+	"strings"
+)
+
+const (
+	// frameworkMatchThreshold is the minimum similarity for a fuzzy framework match.
+	frameworkMatchThreshold = 0.6
+	// outcomeMatchThreshold is the minimum share of a template's outcome words
+	// that must appear in the query for a fuzzy sequence match.
+	outcomeMatchThreshold = 0.5
+	// verticalMatchBonus favours templates written for the requested vertical.
+	verticalMatchBonus = 0.25
+)
+
+// outcomeStopWords are ignored when comparing outcomes.
+var outcomeStopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "the": true, "to": true, "of": true,
+	"for": true, "our": true, "my": true, "from": true, "into": true, "with": true,
+}
+
+// findFramework matches a framework by name or acronym, then by similarity.
+func (s *snapshot) findFramework(query string) *Framework {
+	plain := strings.ReplaceAll(query, "_", " ")
+	plain = strings.TrimSpace(strings.TrimSuffix(plain, " framework"))
+	if fw := s.exactFramework(plain); fw != nil {
+		return fw
+	}
+
+	var bestMatch *Framework
+	bestScore := 0.0
+	for _, key := range sortedKeys(s.Frameworks) {
+		fw := s.Frameworks[key]
+		score := similarityScore(plain, key, fw.Name)
+		if score > bestScore && score > frameworkMatchThreshold {
+			bestScore = score
+			bestMatch = fw
+		}
+	}
+	return bestMatch
+}
+
+// exactFramework matches a framework by key, name or acronym, ignoring case.
+func (s *snapshot) exactFramework(name string) *Framework {
+	if fw := s.Frameworks[normalizeKey(name)]; fw != nil {
+		return fw
+	}
+	for _, key := range sortedKeys(s.Frameworks) {
+		fw := s.Frameworks[key]
+		if strings.EqualFold(fw.Name, name) || strings.EqualFold(fw.Acronym, name) {
+			return fw
+		}
+	}
+	return nil
+}
+
+// findSequenceTemplate implements the lookup order documented on GetSequenceTemplate.
+func (s *snapshot) findSequenceTemplate(outcome, vertical string) *SequenceTemplate {
+	outcomeKey := normalizeKey(outcome)
+	if outcomeKey == "" {
+		return nil
+	}
+	if template := s.SequenceTemplates[outcomeKey+"_"+normalizeKey(vertical)]; template != nil {
+		return template
+	}
+	if template := s.SequenceTemplates[outcomeKey]; template != nil {
+		return template
+	}
+
+	want, _ := s.ResolveVertical(vertical)
+	if template := s.bestOutcomeMatch(outcome, want, false); template != nil {
+		return template
+	}
+
+	// Fallback: generic DTC templates
+	return s.bestOutcomeMatch(outcome, fallbackVertical, true)
+}
+
+// bestOutcomeMatch scores every template against the outcome query. When
+// onlyVertical is set, templates for other verticals are skipped; otherwise
+// templates for vertical get a bonus.
+func (s *snapshot) bestOutcomeMatch(outcome, vertical string, onlyVertical bool) *SequenceTemplate {
+	queryWords := significantWords(outcome)
+	if len(queryWords) == 0 {
+		return nil
+	}
+
+	var bestMatch *SequenceTemplate
+	bestScore := 0.0
+	for _, key := range sortedKeys(s.SequenceTemplates) {
+		template := s.SequenceTemplates[key]
+		templateVertical, _ := s.ResolveVertical(template.Vertical)
+		sameVertical := vertical != "" && templateVertical == vertical
+		if onlyVertical && !sameVertical {
+			continue
+		}
+
+		score := outcomeScore(queryWords, templateWords(key, template, templateVertical))
+		if score < outcomeMatchThreshold {
+			continue
+		}
+		if sameVertical {
+			score += verticalMatchBonus
+		}
+		if score > bestScore {
+			bestScore = score
+			bestMatch = template
+		}
+	}
+	return bestMatch
+}
+
+// templateWords returns the words describing a template's outcome, taken from
+// its key and Outcome with the vertical name removed.
+func templateWords(key string, template *SequenceTemplate, vertical string) []string {
+	seen := make(map[string]bool)
+	var words []string
+	for _, w := range append(significantWords(key), significantWords(template.Outcome)...) {
+		if seen[w] || w == vertical || w == strings.ToLower(template.Vertical) {
+			continue
+		}
+		seen[w] = true
+		words = append(words, w)
+	}
+	return words
+}
+
+// outcomeScore is the share of template words that appear in the query.
+func outcomeScore(queryWords, templateWords []string) float64 {
+	if len(templateWords) == 0 {
+		return 0
+	}
+	matched := 0
+	for _, tw := range templateWords {
+		for _, qw := range queryWords {
+			if wordsMatch(qw, tw) {
+				matched++
+				break
+			}
+		}
+	}
+	return float64(matched) / float64(len(templateWords))
+}
+
+// wordsMatch treats words sharing a stem-like prefix as equal, so
+// "abandoned" matches "abandonment" and "carts" matches "cart".
+func wordsMatch(a, b string) bool {
+	if a == b {
+		return true
+	}
+	const minPrefix = 4
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	shorter := min(len(a), len(b))
+	return n >= minPrefix && (n == shorter || n >= 6)
+}
+
+// significantWords lowercases and splits text, dropping punctuation and stop words.
+func significantWords(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+	})
+	words := fields[:0]
+	for _, f := range fields {
+		if !outcomeStopWords[f] {
+			words = append(words, f)
+		}
+	}
+	return words
+}
+
+// similarityScore computes simple semantic similarity
+func similarityScore(query, key, name string) float64 {
+	words := strings.Fields(strings.ToLower(query))
+	score := 0.0
+
+	for _, word := range words {
+		if strings.Contains(strings.ToLower(key), word) || strings.Contains(strings.ToLower(name), word) {
+			score += 1.0 / float64(len(words))
+		}
+	}
+
+	return score
+}
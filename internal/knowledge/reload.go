@@ -0,0 +1,249 @@
+package knowledge
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"JourneyBuilder/internal/logger"
+)
+
+// Paths locates the three knowledge files.
+type Paths struct {
+	Frameworks string
+	Sequences  string
+	Verticals  string
+}
+
+// DefaultPaths returns the standard file locations inside dir.
+func DefaultPaths(dir string) Paths {
+	return Paths{
+		Frameworks: filepath.Join(dir, "frameworks.json"),
+		Sequences:  filepath.Join(dir, "sequence.json"),
+		Verticals:  filepath.Join(dir, "verticals.json"),
+	}
+}
+
+// KnowledgeBase serves frameworks, sequence templates and vertical guidance
+// from the data/knowledge files. The loaded content is an immutable snapshot
+// that Reload swaps atomically, so readers never see a half-loaded state.
+type KnowledgeBase struct {
+	paths   Paths
+	current atomic.Pointer[snapshot]
+
+	mu          sync.Mutex // guards status and fingerprint
+	status      Status
+	fingerprint map[string]fileStamp
+}
+
+// Status reports what the knowledge base is serving and how the last reload went.
+type Status struct {
+	Version     int        `json:"version"` // incremented on every successful load
+	LoadedAt    time.Time  `json:"loadedAt"`
+	LastChecked time.Time  `json:"lastChecked,omitempty"`
+	LastError   string     `json:"lastError,omitempty"` // set while the files on disk are invalid
+	LastErrorAt *time.Time `json:"lastErrorAt,omitempty"`
+	Frameworks  int        `json:"frameworks"`
+	Sequences   int        `json:"sequences"`
+	Verticals   int        `json:"verticals"`
+	Files       []string   `json:"files"`
+}
+
+// fileStamp identifies one version of a file on disk.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+	exists  bool
+}
+
+// NewKnowledgeBase loads and initializes the KB from JSON.
+// It fails if any file is missing from both disk and the embedded assets,
+// or does not match the schema.
+func NewKnowledgeBase(frameworksPath, sequencesPath, verticalsPath string) (*KnowledgeBase, error) {
+	kb := &KnowledgeBase{
+		paths: Paths{Frameworks: frameworksPath, Sequences: sequencesPath, Verticals: verticalsPath},
+	}
+	if err := kb.Reload(); err != nil {
+		return nil, err
+	}
+	return kb, nil
+}
+
+// Reload reads and validates the knowledge files and, if they are valid,
+// swaps them in atomically. On failure the previous content keeps serving
+// and the error is recorded in Status.
+func (kb *KnowledgeBase) Reload() error {
+	kb.mu.Lock()
+	defer kb.mu.Unlock()
+	return kb.reloadLocked()
+}
+
+func (kb *KnowledgeBase) reloadLocked() error {
+	stamps := kb.stampFiles()
+	now := time.Now()
+	kb.status.LastChecked = now
+
+	snap, err := loadSnapshot(kb.paths)
+	if err != nil {
+		kb.status.LastError = err.Error()
+		kb.status.LastErrorAt = &now
+		// Remember the stamps so a bad file is reported once, not on every poll
+		kb.fingerprint = stamps
+		return err
+	}
+
+	kb.swap(snap, stamps, now)
+	return nil
+}
+
+// swap installs snap as the current content. Callers must hold kb.mu.
+func (kb *KnowledgeBase) swap(snap *snapshot, stamps map[string]fileStamp, now time.Time) {
+	kb.current.Store(snap)
+	kb.fingerprint = stamps
+	kb.status.Version++
+	kb.status.LoadedAt = now
+	kb.status.LastError = ""
+	kb.status.LastErrorAt = nil
+	kb.status.Frameworks = len(snap.Frameworks)
+	kb.status.Sequences = len(snap.SequenceTemplates)
+	kb.status.Verticals = len(snap.VerticalGuides)
+	kb.status.Files = []string{kb.paths.Frameworks, kb.paths.Sequences, kb.paths.Verticals}
+}
+
+// Watch polls the knowledge files every interval and reloads them when any
+// of them changes. It returns when ctx is cancelled.
+func (kb *KnowledgeBase) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			kb.checkForChanges()
+		}
+	}
+}
+
+// checkForChanges reloads the files if their stamps differ from the last load attempt.
+func (kb *KnowledgeBase) checkForChanges() {
+	kb.mu.Lock()
+	defer kb.mu.Unlock()
+
+	stamps := kb.stampFiles()
+	kb.status.LastChecked = time.Now()
+	if sameStamps(stamps, kb.fingerprint) {
+		return
+	}
+
+	if err := kb.reloadLocked(); err != nil {
+		logger.Printf("⚠️  Knowledge base reload failed, keeping version %d: %v", kb.status.Version, err)
+		return
+	}
+	logger.Printf("✓ Knowledge base reloaded (version %d)", kb.status.Version)
+}
+
+// Status returns a copy of the current reload status.
+func (kb *KnowledgeBase) Status() Status {
+	kb.mu.Lock()
+	defer kb.mu.Unlock()
+	status := kb.status
+	status.Files = append([]string(nil), kb.status.Files...)
+	return status
+}
+
+func (kb *KnowledgeBase) stampFiles() map[string]fileStamp {
+	stamps := make(map[string]fileStamp, 3)
+	for _, path := range []string{kb.paths.Frameworks, kb.paths.Sequences, kb.paths.Verticals} {
+		info, err := os.Stat(path)
+		if err != nil {
+			stamps[path] = fileStamp{}
+			continue
+		}
+		stamps[path] = fileStamp{modTime: info.ModTime(), size: info.Size(), exists: true}
+	}
+	return stamps
+}
+
+func sameStamps(a, b map[string]fileStamp) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for path, stamp := range a {
+		other, ok := b[path]
+		if !ok || stamp.exists != other.exists || stamp.size != other.size || !stamp.modTime.Equal(other.modTime) {
+			return false
+		}
+	}
+	return true
+}
+
+func (kb *KnowledgeBase) snapshot() *snapshot {
+	return kb.current.Load()
+}
+
+// GetFramework retrieves a copywriting framework by key, name or acronym,
+// falling back to fuzzy matching.
+func (kb *KnowledgeBase) GetFramework(name string) *Framework {
+	return kb.snapshot().GetFramework(name)
+}
+
+// GetSequenceTemplate retrieves the best pre-built sequence template for an outcome and vertical.
+func (kb *KnowledgeBase) GetSequenceTemplate(outcome, vertical string) *SequenceTemplate {
+	return kb.snapshot().GetSequenceTemplate(outcome, vertical)
+}
+
+// GetVerticalGuidance returns guidance for a specific vertical or one of its aliases.
+func (kb *KnowledgeBase) GetVerticalGuidance(vertical string) VerticalGuidance {
+	return kb.snapshot().GetVerticalGuidance(vertical)
+}
+
+// ResolveVertical maps a vertical name or alias to its guidance key.
+func (kb *KnowledgeBase) ResolveVertical(vertical string) (string, bool) {
+	return kb.snapshot().ResolveVertical(vertical)
+}
+
+// GetFrameworksForVertical returns the frameworks recommended for a vertical.
+func (kb *KnowledgeBase) GetFrameworksForVertical(vertical string) []*Framework {
+	return kb.snapshot().GetFrameworksForVertical(vertical)
+}
+
+// ExecutionFrameworks returns the frameworks to apply when generating a sequence:
+// the template's, else the vertical's, else a default set.
+func (kb *KnowledgeBase) ExecutionFrameworks(template *SequenceTemplate, vertical string) []*Framework {
+	return kb.snapshot().ExecutionFrameworks(template, vertical)
+}
+
+// ListFrameworks returns all frameworks ordered by key.
+func (kb *KnowledgeBase) ListFrameworks() []*Framework {
+	return kb.snapshot().ListFrameworks()
+}
+
+// ListSequencesForVertical returns the templates for a vertical, or all templates when vertical is empty.
+func (kb *KnowledgeBase) ListSequencesForVertical(vertical string) map[string]*SequenceTemplate {
+	return kb.snapshot().ListSequencesForVertical(vertical)
+}
+
+// ExtractRelevantContext builds the minimal knowledge context for a prompt.
+// All lookups use the same snapshot, even if a reload happens concurrently.
+func (kb *KnowledgeBase) ExtractRelevantContext(outcome, vertical, currentStep string) string {
+	return kb.snapshot().ExtractRelevantContext(outcome, vertical, currentStep)
+}
+
+// ExtractRelevantContextWithTouchPoints is ExtractRelevantContext with the
+// template's touch points replaced by touchPoints when it is positive.
+func (kb *KnowledgeBase) ExtractRelevantContextWithTouchPoints(outcome, vertical, currentStep string, touchPoints int) string {
+	return kb.snapshot().ExtractRelevantContextWithTouchPoints(outcome, vertical, currentStep, touchPoints)
+}
+
+// String implements fmt.Stringer for log output.
+func (kb *KnowledgeBase) String() string {
+	status := kb.Status()
+	return fmt.Sprintf("knowledge base v%d (%d frameworks, %d sequences, %d verticals)",
+		status.Version, status.Frameworks, status.Sequences, status.Verticals)
+}
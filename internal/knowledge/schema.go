@@ -0,0 +1,117 @@
+package knowledge
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"JourneyBuilder/internal/branching"
+)
+
+// FieldError reports a missing or invalid field in a knowledge file.
+type FieldError struct {
+	Field   string // dotted JSON path, e.g. "frameworks.aida.components"
+	Message string
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("field %q: %s", e.Field, e.Message)
+}
+
+// Validate checks that a framework has every required field.
+func (fw *Framework) Validate(key string) []error {
+	prefix := "frameworks." + key
+	var errs []error
+	if fw == nil {
+		return []error{&FieldError{Field: prefix, Message: "must be an object"}}
+	}
+	errs = appendRequired(errs, prefix+".name", fw.Name)
+	errs = appendRequired(errs, prefix+".acronym", fw.Acronym)
+	errs = appendRequiredList(errs, prefix+".components", fw.Components)
+	errs = appendRequiredList(errs, prefix+".best_for", fw.BestFor)
+	return errs
+}
+
+// Validate checks that a sequence template has every required field.
+func (st *SequenceTemplate) Validate(key string) []error {
+	prefix := "sequences." + key
+	var errs []error
+	if st == nil {
+		return []error{&FieldError{Field: prefix, Message: "must be an object"}}
+	}
+	errs = appendRequired(errs, prefix+".outcome", st.Outcome)
+	errs = appendRequired(errs, prefix+".vertical", st.Vertical)
+	errs = appendRequired(errs, prefix+".duration", st.Duration)
+	errs = appendRequired(errs, prefix+".cadence", st.CadenceString)
+	if st.TouchPoints <= 0 {
+		errs = append(errs, &FieldError{Field: prefix + ".touch_points", Message: "must be greater than 0"})
+	}
+	errs = appendRequiredList(errs, prefix+".frameworks", st.Frameworks)
+	errs = appendRequiredList(errs, prefix+".key_messages", st.KeyMessages)
+
+	rules, err := branching.Parse(st.BranchingLogic)
+	if err != nil {
+		errs = append(errs, &FieldError{Field: prefix + ".branching_logic", Message: err.Error()})
+	}
+	for _, r := range rules {
+		for _, n := range []int{r.When.Email, r.Then.Email} {
+			if n > st.TouchPoints && st.TouchPoints > 0 {
+				errs = append(errs, &FieldError{Field: prefix + ".branching_logic",
+					Message: fmt.Sprintf("%q refers to email %d but touch_points is %d", r.String(), n, st.TouchPoints)})
+			}
+		}
+	}
+	return errs
+}
+
+// Validate checks that vertical guidance has every required field.
+func (vg VerticalGuidance) Validate(key string) []error {
+	prefix := "verticals." + key
+	var errs []error
+	errs = appendRequired(errs, prefix+".vertical_name", vg.VerticalName)
+	errs = appendRequiredList(errs, prefix+".characteristics", vg.Characteristics)
+	errs = appendRequiredList(errs, prefix+".key_principles", vg.KeyPrinciples)
+	return errs
+}
+
+// validateFile runs validate over every entry of a decoded file in key order
+// and joins the failures into a single error prefixed with the file path.
+func validateFile[T any](path, section string, entries map[string]T, validate func(T, string) []error) error {
+	if entries == nil {
+		return fmt.Errorf("%s: %w", path, &FieldError{Field: section, Message: "is required"})
+	}
+
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var errs []error
+	for _, key := range keys {
+		for _, err := range validate(entries[key], key) {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func appendRequired(errs []error, field, value string) []error {
+	if strings.TrimSpace(value) == "" {
+		return append(errs, &FieldError{Field: field, Message: "is required"})
+	}
+	return errs
+}
+
+func appendRequiredList(errs []error, field string, values []string) []error {
+	if len(values) == 0 {
+		return append(errs, &FieldError{Field: field, Message: "must contain at least one entry"})
+	}
+	for i, v := range values {
+		if strings.TrimSpace(v) == "" {
+			errs = append(errs, &FieldError{Field: fmt.Sprintf("%s[%d]", field, i), Message: "must not be empty"})
+		}
+	}
+	return errs
+}
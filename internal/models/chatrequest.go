@@ -1,6 +1,9 @@
 package models
 
-import "JourneyBuilder/internal/instruction"
+import (
+	"JourneyBuilder/internal/instruction"
+	"JourneyBuilder/internal/journey"
+)
 
 // ChatRequest is the payload received from the frontend.
 type ChatRequest struct {
@@ -20,6 +23,9 @@ type ChatResponse struct {
 	CurrentCircle      string `json:"currentCircle,omitempty"`
 	ProposedOutcome    string `json:"proposedOutcome,omitempty"`
 	Error              string `json:"error,omitempty"`
+
+	// Journey is the sequence parsed from Message at StepExecution.
+	Journey *journey.Journey `json:"journey,omitempty"`
 }
 
 // HealthCheckResponse is used for /health endpoint.
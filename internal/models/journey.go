@@ -0,0 +1,84 @@
+package models
+
+import (
+	"JourneyBuilder/internal/journey"
+	"JourneyBuilder/internal/simulator"
+	"JourneyBuilder/internal/testsend"
+)
+
+// JourneyBrief is everything needed to generate a journey without the
+// 8-step conversation: POST /api/generate-journey
+type JourneyBrief struct {
+	USP              string `json:"usp"`
+	ICP              string `json:"icp"`
+	Vertical         string `json:"vertical"`
+	CircleOfTrust    string `json:"circleOfTrust"`
+	Outcome          string `json:"outcome"`
+	TouchPoints      int    `json:"touchPoints,omitempty"`      // optional; defaults to the sequence template
+	BaseSystemPrompt string `json:"baseSystemPrompt,omitempty"` // optional override
+}
+
+// JourneyResponse returns a generated journey together with the raw model output.
+type JourneyResponse struct {
+	Journey  *journey.Journey `json:"journey"`
+	Message  string           `json:"message"`
+	Template string           `json:"template,omitempty"` // outcome of the matched sequence template
+}
+
+// JourneyPreviewResponse returns a journey skeleton: emails without body copy.
+type JourneyPreviewResponse struct {
+	Journey   *journey.Journey `json:"journey"`
+	Template  string           `json:"template,omitempty"`
+	Generated bool             `json:"generated"` // false when subject lines are the template's key messages
+}
+
+// UpdateDelaysRequest submits a journey with edited day delays: POST /api/update-delays
+type UpdateDelaysRequest struct {
+	Journey *journey.Journey `json:"journey"`
+}
+
+// UpdateDelaysResponse returns the journey with normalized delays. Notes
+// list the delays that were changed; warnings list where the timing departs
+// from the sequence template.
+type UpdateDelaysResponse struct {
+	Journey  *journey.Journey `json:"journey"`
+	Template string           `json:"template,omitempty"`
+	Notes    []string         `json:"notes,omitempty"`
+	Warnings []string         `json:"warnings,omitempty"`
+}
+
+// GenerateStepRequest asks for one email of a journey to be rewritten: POST /api/generate-step
+type GenerateStepRequest struct {
+	Journey      *journey.Journey `json:"journey"`
+	EmailNumber  int              `json:"emailNumber"`
+	Instructions string           `json:"instructions,omitempty"` // e.g. "shorter", "more urgency"
+}
+
+// GenerateStepResponse returns the journey with the rewritten email in place.
+type GenerateStepResponse struct {
+	Journey *journey.Journey `json:"journey"`
+	Email   journey.Email    `json:"email"`
+}
+
+// SimulateJourneyRequest plays a journey against a scripted subscriber: POST /api/simulate-journey
+type SimulateJourneyRequest struct {
+	Journey *journey.Journey  `json:"journey"`
+	Events  []simulator.Event `json:"events"` // e.g. [{"event":"opened","day":2},{"event":"clicked","day":5}]
+}
+
+// TestSendRequest delivers a stored journey to the test SMTP server:
+// POST /api/journeys/{id}/test-send
+type TestSendRequest struct {
+	To              string  `json:"to,omitempty"`              // overrides EMAIL_TO; must be listed in TEST_SEND_RECIPIENTS
+	TimeCompression float64 `json:"timeCompression,omitempty"` // e.g. 86400 plays each day out in a second
+}
+
+// TestSendResponse reports the delivery of every email, in send order.
+type TestSendResponse struct {
+	ID       string            `json:"id"`
+	Version  int               `json:"version"`
+	SMTPAddr string            `json:"smtpAddr"`
+	Sent     int               `json:"sent"`
+	Failed   int               `json:"failed"`
+	Results  []testsend.Result `json:"results"`
+}
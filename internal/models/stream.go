@@ -0,0 +1,52 @@
+package models
+
+// StreamEventType names an event in the /api/v1/chat/stream SSE protocol.
+type StreamEventType string
+
+const (
+	StreamEventStep        StreamEventType = "step"         // StepPayload, sent first
+	StreamEventContext     StreamEventType = "context"      // ContextPayload, sent after step
+	StreamEventToken       StreamEventType = "token"        // TokenPayload, one per model token batch
+	StreamEventSequenceRow StreamEventType = "sequence_row" // SequenceRowPayload, one per completed table row
+	StreamEventError       StreamEventType = "error"        // ErrorPayload, terminal
+	StreamEventDone        StreamEventType = "done"         // ChatResponse, terminal
+)
+
+// StreamEvent is a typed event emitted while a chat response is generated.
+// Data holds the payload type documented on the event type.
+type StreamEvent struct {
+	Type StreamEventType
+	Data any
+}
+
+// StepPayload announces the workflow step being answered.
+type StepPayload struct {
+	Step int    `json:"step"`
+	Name string `json:"name"`
+}
+
+// ContextPayload carries the context extracted from the conversation.
+type ContextPayload struct {
+	ExtractedUSP       string `json:"extractedUSP,omitempty"`
+	ExtractedICP       string `json:"extractedICP,omitempty"`
+	IdentifiedVertical string `json:"identifiedVertical,omitempty"`
+	CurrentCircle      string `json:"currentCircle,omitempty"`
+	ProposedOutcome    string `json:"proposedOutcome,omitempty"`
+}
+
+// TokenPayload is a piece of model output.
+type TokenPayload struct {
+	Text string `json:"text"`
+}
+
+// SequenceRowPayload is one row of the Step 8 sequence table.
+type SequenceRowPayload struct {
+	EmailNumber int    `json:"emailNumber"`
+	SubjectLine string `json:"subjectLine"`
+	DayDelay    int    `json:"dayDelay"`
+}
+
+// ErrorPayload describes a failure that ended the stream.
+type ErrorPayload struct {
+	Message string `json:"message"`
+}
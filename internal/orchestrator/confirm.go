@@ -0,0 +1,36 @@
+package orchestrator
+
+import (
+	"fmt"
+
+	"JourneyBuilder/internal/journey"
+)
+
+// CheckJourney validates a journey before it is confirmed. Problems (broken
+// structure or compliance failures) block confirmation; warnings do not.
+func (o *Orchestrator) CheckJourney(j *journey.Journey) (problems, warnings []string) {
+	if err := j.Validate(); err != nil {
+		return []string{err.Error()}, nil
+	}
+
+	for i, email := range j.Emails {
+		if i > 0 && email.Number <= j.Emails[i-1].Number {
+			problems = append(problems, "emails must be ordered by number")
+		}
+		switch {
+		case i == 0 && email.DayDelay != 0:
+			problems = append(problems, fmt.Sprintf("email %d: the first email must be on day 0", email.Number))
+		case i > 0 && email.DayDelay < j.Emails[i-1].DayDelay:
+			problems = append(problems, fmt.Sprintf("email %d: day %d is before the previous email", email.Number, email.DayDelay))
+		}
+
+		emailProblems, emailWarnings := o.outputValidator.CheckEmail(email.Subject, email.Body)
+		for _, p := range emailProblems {
+			problems = append(problems, fmt.Sprintf("email %d: %s", email.Number, p))
+		}
+		for _, w := range emailWarnings {
+			warnings = append(warnings, fmt.Sprintf("email %d: %s", email.Number, w))
+		}
+	}
+	return problems, warnings
+}
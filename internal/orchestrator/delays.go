@@ -0,0 +1,31 @@
+package orchestrator
+
+import (
+	"errors"
+	"fmt"
+
+	"JourneyBuilder/internal/models"
+)
+
+// ErrInvalidJourney is returned when a submitted journey is malformed.
+var ErrInvalidJourney = errors.New("invalid journey")
+
+// UpdateDelays normalizes edited day delays and checks them against the
+// sequence template for the journey's outcome and vertical. Only timing
+// changes: subjects and copy are returned as submitted, with no LLM call.
+func (o *Orchestrator) UpdateDelays(req *models.UpdateDelaysRequest) (*models.UpdateDelaysResponse, error) {
+	j := req.Journey
+	if err := j.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidJourney, err)
+	}
+
+	resp := &models.UpdateDelaysResponse{
+		Journey: j,
+		Notes:   j.NormalizeDelays(),
+	}
+	if template := o.kb.GetSequenceTemplate(j.Outcome, j.Vertical); template != nil {
+		resp.Template = template.Outcome
+		resp.Warnings = template.CheckDelays(j.Delays())
+	}
+	return resp, nil
+}
@@ -0,0 +1,151 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"JourneyBuilder/internal/branching"
+	"JourneyBuilder/internal/instruction"
+	"JourneyBuilder/internal/journey"
+	"JourneyBuilder/internal/knowledge"
+	"JourneyBuilder/internal/models"
+	"JourneyBuilder/internal/services"
+)
+
+// maxTouchPoints caps the email count a brief may request.
+const maxTouchPoints = 20
+
+var (
+	// ErrInvalidBrief is returned when a journey brief is incomplete or rejected by input validation.
+	ErrInvalidBrief = errors.New("invalid brief")
+	// ErrNoJourney is returned when the model response contains no parseable emails.
+	ErrNoJourney = errors.New("model response did not contain a journey")
+)
+
+// GenerateJourney produces a journey straight from a brief, skipping the
+// conversation: it composes the StepExecution prompt from the brief and the
+// knowledge base and parses the result.
+func (o *Orchestrator) GenerateJourney(ctx context.Context, brief *models.JourneyBrief) (*models.JourneyResponse, error) {
+	if err := o.validateBrief(brief); err != nil {
+		return nil, err
+	}
+
+	userCtx := briefContext(brief)
+	step := instruction.StepExecution
+	stepStr := workflowStepToString(step)
+	template := o.kb.GetSequenceTemplate(userCtx.ProposedOutcome, userCtx.IdentifiedVertical)
+
+	basePrompt := brief.BaseSystemPrompt
+	if basePrompt == "" {
+		basePrompt = instruction.BaseSystemPrompt
+	}
+	composerCfg := &instruction.ComposerConfig{
+		BaseSystemPrompt: basePrompt,
+		WorkflowStep:     step,
+		UserContext:      *userCtx,
+		VerticalType:     userCtx.IdentifiedVertical,
+		KnowledgeContext: o.kb.ExtractRelevantContextWithTouchPoints(userCtx.ProposedOutcome, userCtx.IdentifiedVertical, stepStr, brief.TouchPoints),
+		OutputFormat:     outputFormatFor(step),
+	}
+
+	resp, err := o.llm.SendRequest(ctx, &services.RequestBuilder{
+		SystemPrompt: composerCfg.ComposeInstructions(),
+		UserMessage:  briefMessage(brief),
+		Temperature:  0.7,
+		MaxTokens:    3000,
+		WorkflowStep: stepStr,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	prepared := &preparedRequest{userCtx: userCtx, step: step}
+	chatResp := o.finish(prepared, resp.Text)
+	if chatResp.Journey == nil {
+		return nil, ErrNoJourney
+	}
+
+	result := &models.JourneyResponse{Journey: chatResp.Journey, Message: resp.Text}
+	if template != nil {
+		result.Template = template.Outcome
+	}
+	return result, nil
+}
+
+// branchesFor returns the template's branches that fit j: rules naming an
+// email the journey does not have are dropped, since the model may write
+// fewer emails than the template plans.
+func branchesFor(template *knowledge.SequenceTemplate, j *journey.Journey) []branching.Rule {
+	var rules []branching.Rule
+	for _, r := range template.Branches() {
+		if (r.When.Email == 0 || j.Email(r.When.Email) != nil) && (r.Then.Email == 0 || j.Email(r.Then.Email) != nil) {
+			rules = append(rules, r)
+		}
+	}
+	return rules
+}
+
+// validateBrief checks required fields and runs every field through the input validator.
+func (o *Orchestrator) validateBrief(brief *models.JourneyBrief) error {
+	required := []struct{ name, value string }{
+		{"usp", brief.USP},
+		{"icp", brief.ICP},
+		{"vertical", brief.Vertical},
+		{"circleOfTrust", brief.CircleOfTrust},
+		{"outcome", brief.Outcome},
+	}
+	for _, field := range required {
+		if strings.TrimSpace(field.value) == "" {
+			return fmt.Errorf("%w: %s is required", ErrInvalidBrief, field.name)
+		}
+		if err := o.inputValidator.ValidateInput(field.value); err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrInvalidBrief, field.name, err)
+		}
+	}
+	if brief.TouchPoints < 0 || brief.TouchPoints > maxTouchPoints {
+		return fmt.Errorf("%w: touchPoints must be between 1 and %d when set", ErrInvalidBrief, maxTouchPoints)
+	}
+	return nil
+}
+
+// briefContext fills the user context the chat flow would have extracted.
+func briefContext(brief *models.JourneyBrief) *instruction.UserContext {
+	return &instruction.UserContext{
+		ExtractedUSP:         strings.TrimSpace(brief.USP),
+		ExtractedICP:         strings.TrimSpace(brief.ICP),
+		IdentifiedVertical:   strings.TrimSpace(brief.Vertical),
+		CurrentCircleOfTrust: strings.TrimSpace(brief.CircleOfTrust),
+		ProposedOutcome:      strings.TrimSpace(brief.Outcome),
+	}
+}
+
+// briefMessage is the user turn sent with a brief, standing in for the conversation.
+func briefMessage(brief *models.JourneyBrief) string {
+	msg := "Generate the complete email sequence now."
+	if brief.TouchPoints > 0 {
+		msg += fmt.Sprintf(" Use exactly %d emails.", brief.TouchPoints)
+	}
+	return msg
+}
+
+// journeyFromText parses a StepExecution response, tagging it with the
+// outcome and vertical from userCtx. Send times come from the matching
+// sequence template's cadence rather than the model's day delays, which
+// cannot express sends a few hours apart; without a template the model's
+// delays are kept.
+func (o *Orchestrator) journeyFromText(text string, userCtx *instruction.UserContext) *journey.Journey {
+	j := journey.Parse(text)
+	if j == nil {
+		return nil
+	}
+	j.Outcome = userCtx.ProposedOutcome
+	j.Vertical = userCtx.IdentifiedVertical
+
+	if template := o.kb.GetSequenceTemplate(j.Outcome, j.Vertical); template != nil {
+		j.SetSendOffsets(template.SendOffsets(len(j.Emails)))
+		j.Branches = branchesFor(template, j)
+	}
+	return j
+}
@@ -0,0 +1,115 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"JourneyBuilder/internal/knowledge"
+	"JourneyBuilder/internal/models"
+	"JourneyBuilder/internal/services"
+	"JourneyBuilder/internal/validation"
+)
+
+// newTestOrchestrator runs against the shipped knowledge base and a fake
+// provider.
+func newTestOrchestrator(t *testing.T) (*Orchestrator, *services.FakeProvider) {
+	t.Helper()
+	paths := knowledge.DefaultPaths(filepath.Join("..", "..", "data", "knowledge"))
+	kb, err := knowledge.NewKnowledgeBase(paths.Frameworks, paths.Sequences, paths.Verticals)
+	if err != nil {
+		t.Fatal(err)
+	}
+	llm := services.NewFakeProvider(nil)
+	return NewOrchestrator(llm, kb, validation.NewInputValidator(), validation.NewOutputValidator()), llm
+}
+
+// scriptedJourney is a StepExecution response with n emails.
+func scriptedJourney(n int) string {
+	var sb strings.Builder
+	sb.WriteString("| Email # | Subject Line | Day Delay |\n| --- | --- | --- |\n")
+	for i := 1; i <= n; i++ {
+		fmt.Fprintf(&sb, "| %d | Email number %d | %d |\n", i, i, (i-1)*2)
+	}
+	for i := 1; i <= n; i++ {
+		fmt.Fprintf(&sb, "\nEmail %d: Email number %d\nSubject: Email number %d\nPreview: Preview %d\n", i, i, i, i)
+		fmt.Fprintf(&sb, "Framework: AIDA\nBody of email %d.\nCTA: Shop now\n", i)
+	}
+	return sb.String()
+}
+
+func testBrief() *models.JourneyBrief {
+	return &models.JourneyBrief{
+		USP:           "Handmade leather bags that last a lifetime",
+		ICP:           "Professionals who buy once and buy well",
+		Vertical:      " DTC ",
+		CircleOfTrust: "Follower",
+		Outcome:       " First Purchase Acquisition ",
+	}
+}
+
+func TestGenerateJourneyTouchPoints(t *testing.T) {
+	o, llm := newTestOrchestrator(t)
+	brief := testBrief()
+	brief.TouchPoints = 7
+	llm.SetResponse("StepExecution", scriptedJourney(7))
+
+	resp, err := o.GenerateJourney(context.Background(), brief)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Template != "First Purchase Acquisition" {
+		t.Errorf("template = %q, want the first purchase template", resp.Template)
+	}
+	if n := len(resp.Journey.Emails); n != 7 {
+		t.Fatalf("journey has %d emails, want 7", n)
+	}
+	for i := 1; i < len(resp.Journey.Emails); i++ {
+		if resp.Journey.Emails[i].SendOffset() <= resp.Journey.Emails[i-1].SendOffset() {
+			t.Errorf("email %d is not sent after email %d", i+1, i)
+		}
+	}
+
+	reqs := llm.Requests()
+	if len(reqs) != 1 {
+		t.Fatalf("provider got %d requests, want 1", len(reqs))
+	}
+	prompt := reqs[0].SystemPrompt
+	if !strings.Contains(prompt, "Touch Points: 7") || strings.Contains(prompt, "Touch Points: 5") {
+		t.Errorf("prompt does not replace the template's touch points:\n%s", prompt)
+	}
+	if !strings.Contains(reqs[0].UserMessage, "Use exactly 7 emails") {
+		t.Errorf("user message = %q", reqs[0].UserMessage)
+	}
+
+	// Without a count the template's stands
+	llm.Reset()
+	brief.TouchPoints = 0
+	if _, err := o.GenerateJourney(context.Background(), brief); err != nil {
+		t.Fatal(err)
+	}
+	if prompt := llm.Requests()[0].SystemPrompt; !strings.Contains(prompt, "Touch Points: 5\n") {
+		t.Errorf("prompt does not give the template's touch points:\n%s", prompt)
+	}
+}
+
+func TestGenerateJourneyPassesCheck(t *testing.T) {
+	o, _ := newTestOrchestrator(t)
+	// "Sparse: 15, 30, 45 days" lists the sends after the first email
+	brief := testBrief()
+	brief.Vertical, brief.Outcome = "Nonprofit", "Single to Recurring Donor"
+
+	resp, err := o.GenerateJourney(context.Background(), brief)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := resp.Journey.Delays(); !slices.Equal(got, []int{0, 15, 30}) {
+		t.Errorf("day delays = %v, want [0 15 30]", got)
+	}
+	if problems, _ := o.CheckJourney(resp.Journey); len(problems) > 0 {
+		t.Errorf("generated journey fails CheckJourney: %v", problems)
+	}
+}
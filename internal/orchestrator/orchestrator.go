@@ -2,6 +2,7 @@ package orchestrator
 
 import (
 	"context"
+	"strings"
 
 	"JourneyBuilder/internal/instruction"
 	"JourneyBuilder/internal/knowledge"
@@ -10,9 +11,9 @@ import (
 	"JourneyBuilder/internal/validation"
 )
 
-// Orchestrator coordinates validation, context building, prompt composition, and Gemini AI calls.
+// Orchestrator coordinates validation, context building, prompt composition, and LLM calls.
 type Orchestrator struct {
-	geminiService   *services.GeminiService
+	llm             services.LLMProvider
 	contextBuilder  *ContextBuilder
 	kb              *knowledge.KnowledgeBase
 	inputValidator  *validation.InputValidator
@@ -21,13 +22,13 @@ type Orchestrator struct {
 
 // NewOrchestrator wires all core services together.
 func NewOrchestrator(
-	geminiService *services.GeminiService,
+	llm services.LLMProvider,
 	kb *knowledge.KnowledgeBase,
 	inputValidator *validation.InputValidator,
 	outputValidator *validation.OutputValidator,
 ) *Orchestrator {
 	return &Orchestrator{
-		geminiService:   geminiService,
+		llm:             llm,
 		contextBuilder:  NewContextBuilder(20),
 		kb:              kb,
 		inputValidator:  inputValidator,
@@ -35,20 +36,129 @@ func NewOrchestrator(
 	}
 }
 
+// preparedRequest carries everything derived from a chat request before the LLM call.
+type preparedRequest struct {
+	userCtx *instruction.UserContext
+	step    instruction.WorkflowStep
+	llmReq  *services.RequestBuilder
+}
+
 // ProcessChatRequest orchestrates the full non-streaming flow.
 func (o *Orchestrator) ProcessChatRequest(
 	ctx context.Context,
 	req *models.ChatRequest,
 	_ bool, // reserved for future flags
 ) (*models.ChatResponse, error) {
-	// 1. Validate input (prompt injection / security)
-	if err := o.inputValidator.ValidateInput(req.CurrentMessage); err != nil {
+	prepared, err := o.prepare(req)
+	if err != nil {
 		return &models.ChatResponse{
 			Message: "I'm sorry, but I cannot fulfill that request as it conflicts with my core operational security protocols.",
 			Error:   err.Error(),
 		}, err
 	}
 
+	// 7. Call the configured LLM provider
+	resp, err := o.llm.SendRequest(ctx, prepared.llmReq)
+	if err != nil {
+		return &models.ChatResponse{
+			Message: "Error processing your request. Please try again.",
+			Error:   err.Error(),
+		}, err
+	}
+
+	return o.finish(prepared, resp.Text), nil
+}
+
+// ProcessChatRequestStream runs the same flow as ProcessChatRequest but emits
+// typed events as the response is generated: step and context first, then
+// tokens (plus sequence rows at StepExecution), and finally done or error.
+func (o *Orchestrator) ProcessChatRequestStream(
+	ctx context.Context,
+	req *models.ChatRequest,
+) (<-chan models.StreamEvent, error) {
+	prepared, err := o.prepare(req)
+	if err != nil {
+		return nil, err
+	}
+
+	chunks, err := o.llm.StreamRequest(ctx, prepared.llmReq)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan models.StreamEvent)
+
+	go func() {
+		defer close(out)
+
+		userCtx := prepared.userCtx
+		header := []models.StreamEvent{
+			{Type: models.StreamEventStep, Data: models.StepPayload{
+				Step: int(prepared.step),
+				Name: prepared.llmReq.WorkflowStep,
+			}},
+			{Type: models.StreamEventContext, Data: models.ContextPayload{
+				ExtractedUSP:       userCtx.ExtractedUSP,
+				ExtractedICP:       userCtx.ExtractedICP,
+				IdentifiedVertical: userCtx.IdentifiedVertical,
+				CurrentCircle:      userCtx.CurrentCircleOfTrust,
+				ProposedOutcome:    userCtx.ProposedOutcome,
+			}},
+		}
+		for _, ev := range header {
+			if !sendEvent(ctx, out, ev) {
+				return
+			}
+		}
+
+		var full strings.Builder
+		rows := &rowScanner{enabled: shouldIncludeTable(prepared.step)}
+		for chunk := range chunks {
+			if chunk.Err != nil {
+				sendEvent(ctx, out, models.StreamEvent{
+					Type: models.StreamEventError,
+					Data: models.ErrorPayload{Message: chunk.Err.Error()},
+				})
+				return
+			}
+			full.WriteString(chunk.Text)
+			if !sendEvent(ctx, out, models.StreamEvent{
+				Type: models.StreamEventToken,
+				Data: models.TokenPayload{Text: chunk.Text},
+			}) {
+				return
+			}
+			for _, row := range rows.feed(chunk.Text) {
+				if !sendEvent(ctx, out, models.StreamEvent{Type: models.StreamEventSequenceRow, Data: row}) {
+					return
+				}
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			return
+		}
+		for _, row := range rows.flush() {
+			if !sendEvent(ctx, out, models.StreamEvent{Type: models.StreamEventSequenceRow, Data: row}) {
+				return
+			}
+		}
+
+		sendEvent(ctx, out, models.StreamEvent{
+			Type: models.StreamEventDone,
+			Data: o.finish(prepared, full.String()),
+		})
+	}()
+
+	return out, nil
+}
+
+// prepare runs steps 1-6: input validation, context extraction, knowledge lookup and prompt composition.
+func (o *Orchestrator) prepare(req *models.ChatRequest) (*preparedRequest, error) {
+	// 1. Validate input (prompt injection / security)
+	if err := o.inputValidator.ValidateInput(req.CurrentMessage); err != nil {
+		return nil, err
+	}
+
 	// 2. Build stateless context
 	userCtx := o.contextBuilder.BuildContext(req)
 
@@ -67,18 +177,12 @@ func (o *Orchestrator) ProcessChatRequest(
 		UserContext:      *userCtx,
 		VerticalType:     userCtx.IdentifiedVertical,
 		KnowledgeContext: kbContext,
-		OutputFormat: instruction.OutputFormat{
-			Type:             "text",
-			IncludeTable:     shouldIncludeTable(currentStep),
-			TableColumns:     []string{"Email #", "Subject Line", "Day Delay"},
-			MaxEmailLength:   750,
-			ReadabilityLevel: "Grade6",
-		},
+		OutputFormat:     outputFormatFor(currentStep),
 	}
 
 	composedPrompt := composerCfg.ComposeInstructions()
 
-	// 6. Build Gemini AI request
+	// 6. Build LLM request
 	// Convert instruction.Message to services.Message
 	convHistory := make([]services.Message, len(userCtx.ConversationHistory))
 	for i, msg := range userCtx.ConversationHistory {
@@ -88,63 +192,67 @@ func (o *Orchestrator) ProcessChatRequest(
 		}
 	}
 
-	geminiReq := &services.RequestBuilder{
-		SystemPrompt:        composedPrompt,
-		UserMessage:         req.CurrentMessage,
-		ConversationHistory: convHistory,
-		Temperature:         0.7,
-		MaxTokens:           3000,
-	}
-
-	// 7. Call Gemini AI
-	resp, err := o.geminiService.SendRequest(ctx, geminiReq)
-	if err != nil {
-		return &models.ChatResponse{
-			Message: "Error processing your request. Please try again.",
-			Error:   err.Error(),
-		}, err
-	}
+	return &preparedRequest{
+		userCtx: userCtx,
+		step:    currentStep,
+		llmReq: &services.RequestBuilder{
+			SystemPrompt:        composedPrompt,
+			UserMessage:         req.CurrentMessage,
+			ConversationHistory: convHistory,
+			Temperature:         0.7,
+			MaxTokens:           3000,
+			WorkflowStep:        stepStr,
+		},
+	}, nil
+}
 
+// finish runs steps 8-9: output validation and building the structured response.
+func (o *Orchestrator) finish(prepared *preparedRequest, text string) *models.ChatResponse {
 	// 8. Validate output (spam/compliance)
-	_ = o.outputValidator.ValidateResponse(resp.Text, currentStep)
+	_ = o.outputValidator.ValidateResponse(text, prepared.step)
 
 	// 9. Return structured response
-	return &models.ChatResponse{
-		Message:            resp.Text,
-		WorkflowStep:       int(currentStep),
+	userCtx := prepared.userCtx
+	resp := &models.ChatResponse{
+		Message:            text,
+		WorkflowStep:       int(prepared.step),
 		ExtractedUSP:       userCtx.ExtractedUSP,
 		ExtractedICP:       userCtx.ExtractedICP,
 		IdentifiedVertical: userCtx.IdentifiedVertical,
 		CurrentCircle:      userCtx.CurrentCircleOfTrust,
 		ProposedOutcome:    userCtx.ProposedOutcome,
-	}, nil
+	}
+	if prepared.step == instruction.StepExecution {
+		resp.Journey = o.journeyFromText(text, userCtx)
+	}
+	return resp
 }
 
-// ProcessChatRequestStream is a placeholder streaming API.
-// For now, it just returns a single chunk channel from the non-streaming call.
-func (o *Orchestrator) ProcessChatRequestStream(
-	ctx context.Context,
-	req *models.ChatRequest,
-) (<-chan string, error) {
-	out := make(chan string, 1)
-
-	go func() {
-		defer close(out)
-		resp, err := o.ProcessChatRequest(ctx, req, true)
-		if err != nil {
-			out <- "Error processing your request. Please try again."
-			return
-		}
-		out <- resp.Message
-	}()
-
-	return out, nil
+// sendEvent delivers an event unless the context is cancelled first.
+func sendEvent(ctx context.Context, out chan<- models.StreamEvent, ev models.StreamEvent) bool {
+	select {
+	case out <- ev:
+		return true
+	case <-ctx.Done():
+		return false
+	}
 }
 
 func shouldIncludeTable(step instruction.WorkflowStep) bool {
 	return step == instruction.StepExecution
 }
 
+// outputFormatFor returns the output format requested from the model at a workflow step.
+func outputFormatFor(step instruction.WorkflowStep) instruction.OutputFormat {
+	return instruction.OutputFormat{
+		Type:             "text",
+		IncludeTable:     shouldIncludeTable(step),
+		TableColumns:     []string{"Email #", "Subject Line", "Day Delay"},
+		MaxEmailLength:   750,
+		ReadabilityLevel: "Grade6",
+	}
+}
+
 // workflowStepToString converts a WorkflowStep to its string representation for knowledge base lookups
 func workflowStepToString(step instruction.WorkflowStep) string {
 	stepMap := map[instruction.WorkflowStep]string{
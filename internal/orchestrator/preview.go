@@ -0,0 +1,128 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"JourneyBuilder/internal/journey"
+	"JourneyBuilder/internal/knowledge"
+	"JourneyBuilder/internal/logger"
+	"JourneyBuilder/internal/models"
+	"JourneyBuilder/internal/services"
+)
+
+const (
+	// previewStep names the skeleton request for providers keyed by workflow step.
+	previewStep = "StepPreview"
+	// previewMaxTokens keeps the skeleton call cheap: a table of subject lines only.
+	previewMaxTokens = 400
+	// defaultTouchPoints is used when neither the brief nor a template sets the email count.
+	defaultTouchPoints = 5
+)
+
+// PreviewJourney returns the skeleton of a journey (email count, subject
+// lines, day delays and framework per email) without body copy. Count,
+// delays and frameworks come from the matching sequence template; subject
+// lines come from a small LLM call. If that call fails, the template's key
+// messages stand in for subject lines and Generated is false.
+func (o *Orchestrator) PreviewJourney(ctx context.Context, brief *models.JourneyBrief) (*models.JourneyPreviewResponse, error) {
+	if err := o.validateBrief(brief); err != nil {
+		return nil, err
+	}
+
+	template := o.kb.GetSequenceTemplate(strings.TrimSpace(brief.Outcome), strings.TrimSpace(brief.Vertical))
+	skeleton := o.templateSkeleton(brief, template)
+	result := &models.JourneyPreviewResponse{Journey: skeleton}
+	if template != nil {
+		result.Template = template.Outcome
+	}
+
+	resp, err := o.llm.SendRequest(ctx, &services.RequestBuilder{
+		SystemPrompt: previewPrompt(brief, skeleton),
+		UserMessage:  "Write the subject lines now.",
+		Temperature:  0.7,
+		MaxTokens:    previewMaxTokens,
+		WorkflowStep: previewStep,
+	})
+	if err != nil {
+		logger.Printf("⚠️  Preview subject lines unavailable, using template only: %v", err)
+		return result, nil
+	}
+
+	generated := journey.Parse(resp.Text)
+	if generated == nil {
+		logger.Printf("⚠️  Preview response had no table, using template only")
+		return result, nil
+	}
+	for i := range skeleton.Emails {
+		email := &skeleton.Emails[i]
+		if row := generated.Email(email.Number); row != nil && row.Subject != "" {
+			email.Subject = row.Subject
+			result.Generated = true
+		}
+	}
+	return result, nil
+}
+
+// templateSkeleton lays out the emails from the sequence template, with its
+// key messages as placeholder subjects.
+func (o *Orchestrator) templateSkeleton(brief *models.JourneyBrief, template *knowledge.SequenceTemplate) *journey.Journey {
+	if template == nil {
+		template = &knowledge.SequenceTemplate{}
+	}
+
+	count := brief.TouchPoints
+	if count == 0 {
+		count = template.TouchPoints
+	}
+	if count == 0 {
+		count = defaultTouchPoints
+	}
+
+	var frameworks []string
+	for _, fw := range o.kb.ExecutionFrameworks(template, brief.Vertical) {
+		frameworks = append(frameworks, fw.Name)
+	}
+	if len(frameworks) == 0 {
+		frameworks = []string{""}
+	}
+
+	offsets := template.SendOffsets(count)
+	userCtx := briefContext(brief)
+	skeleton := &journey.Journey{
+		Outcome:  userCtx.ProposedOutcome,
+		Vertical: userCtx.IdentifiedVertical,
+		Emails:   make([]journey.Email, count),
+	}
+	for i := range skeleton.Emails {
+		subject := fmt.Sprintf("Email %d", i+1)
+		if i < len(template.KeyMessages) {
+			subject = template.KeyMessages[i]
+		}
+		skeleton.Emails[i] = journey.Email{
+			Number:    i + 1,
+			Subject:   subject,
+			Framework: frameworks[i%len(frameworks)],
+		}
+		skeleton.Emails[i].SetSendOffset(offsets[i])
+	}
+	skeleton.Branches = branchesFor(template, skeleton)
+	return skeleton
+}
+
+// previewPrompt asks for subject lines only, for a plan that is already fixed.
+func previewPrompt(brief *models.JourneyBrief, skeleton *journey.Journey) string {
+	var sb strings.Builder
+	sb.WriteString("You write email subject lines. Output ONLY a markdown table with the columns ")
+	sb.WriteString("Email #, Subject Line, Day Delay, Framework and one row per email in the plan below. ")
+	sb.WriteString("Keep each subject under 40 characters. Keep the day delays and frameworks exactly as given. ")
+	sb.WriteString("No other text.\n\n")
+	sb.WriteString(fmt.Sprintf("USP: %s\nICP: %s\nVertical: %s\nCircle of Trust: %s\nOutcome: %s\n\n",
+		brief.USP, brief.ICP, brief.Vertical, brief.CircleOfTrust, brief.Outcome))
+	sb.WriteString("PLAN (email, theme, day delay, framework):\n")
+	for _, email := range skeleton.Emails {
+		sb.WriteString(fmt.Sprintf("%d. %s | day %d | %s\n", email.Number, email.Subject, email.DayDelay, email.Framework))
+	}
+	return sb.String()
+}
@@ -0,0 +1,168 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"JourneyBuilder/internal/journey"
+	"JourneyBuilder/internal/models"
+	"JourneyBuilder/internal/services"
+)
+
+const (
+	// regenerateStep names the single-email request for providers keyed by workflow step.
+	regenerateStep = "StepRegenerateEmail"
+	// regenerateMaxTokens fits one email of MaxEmailLength characters with room to spare.
+	regenerateMaxTokens = 800
+	// maxInstructionsLength bounds the optional rewrite instructions.
+	maxInstructionsLength = 500
+)
+
+// RegenerateEmail rewrites one email of a journey, using its neighbours as
+// context and following the optional instructions. Every other email, and
+// the regenerated email's number, day delay and framework, are returned unchanged.
+func (o *Orchestrator) RegenerateEmail(ctx context.Context, req *models.GenerateStepRequest) (*models.GenerateStepResponse, error) {
+	j := req.Journey
+	if err := j.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidJourney, err)
+	}
+
+	// The caller's email order is kept; only the target is replaced
+	index := -1
+	for i, email := range j.Emails {
+		if email.Number == req.EmailNumber {
+			index = i
+		}
+	}
+	if index < 0 {
+		return nil, fmt.Errorf("%w: email %d is not in the journey", ErrInvalidJourney, req.EmailNumber)
+	}
+
+	instructions := strings.TrimSpace(req.Instructions)
+	if len(instructions) > maxInstructionsLength {
+		return nil, fmt.Errorf("%w: instructions must be at most %d characters", ErrInvalidJourney, maxInstructionsLength)
+	}
+	if instructions != "" {
+		if err := o.inputValidator.ValidateInput(instructions); err != nil {
+			return nil, fmt.Errorf("%w: instructions: %v", ErrInvalidJourney, err)
+		}
+	}
+
+	target := j.Emails[index]
+	resp, err := o.llm.SendRequest(ctx, &services.RequestBuilder{
+		SystemPrompt: o.regeneratePrompt(j, target.Number),
+		UserMessage:  regenerateMessage(target.Number, instructions),
+		Temperature:  0.7,
+		MaxTokens:    regenerateMaxTokens,
+		WorkflowStep: regenerateStep,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	parsed := journey.Parse(resp.Text)
+	if parsed == nil {
+		return nil, ErrNoJourney
+	}
+	rewritten := parsed.Email(target.Number)
+	if rewritten == nil && len(parsed.Emails) == 1 {
+		rewritten = &parsed.Emails[0]
+	}
+	if rewritten == nil {
+		return nil, ErrNoJourney
+	}
+
+	updated := target
+	updated.Subject = firstNonEmpty(rewritten.Subject, target.Subject)
+	updated.PreviewText = firstNonEmpty(rewritten.PreviewText, target.PreviewText)
+	updated.Body = firstNonEmpty(rewritten.Body, target.Body)
+	updated.Framework = firstNonEmpty(target.Framework, rewritten.Framework)
+	updated.CTA = firstNonEmpty(rewritten.CTA, target.CTA)
+	j.Emails[index] = updated
+
+	return &models.GenerateStepResponse{Journey: j, Email: updated}, nil
+}
+
+// regeneratePrompt describes the journey around the email being rewritten,
+// walking a copy of it in number order.
+func (o *Orchestrator) regeneratePrompt(original *journey.Journey, number int) string {
+	j := *original
+	j.Emails = append([]journey.Email(nil), original.Emails...)
+	j.SortEmails()
+	index := 0
+	for i, email := range j.Emails {
+		if email.Number == number {
+			index = i
+		}
+	}
+	target := j.Emails[index]
+
+	var sb strings.Builder
+	sb.WriteString("You are Da Vinci, an expert email marketer. Rewrite ONE email of an existing email sequence. ")
+	sb.WriteString("Keep its role in the sequence: do not repeat what the previous email said and lead naturally into the next one. ")
+	sb.WriteString("Emails must be compliant (CAN-SPAM, GDPR), concise (max 750 chars) and written at Grade 6 readability. ")
+	sb.WriteString("Subject lines must be under 40 characters.\n\n")
+
+	if fw := o.kb.GetFramework(target.Framework); fw != nil {
+		sb.WriteString(fmt.Sprintf("FRAMEWORK: %s (%s): %s\n\n", fw.Name, fw.Acronym, strings.Join(fw.Components, ", ")))
+	}
+	if j.Outcome != "" || j.Vertical != "" {
+		sb.WriteString(fmt.Sprintf("SEQUENCE OUTCOME: %s\nVERTICAL: %s\n\n", j.Outcome, j.Vertical))
+	}
+
+	sb.WriteString(fmt.Sprintf("SEQUENCE: %d emails\n", len(j.Emails)))
+	for i, email := range j.Emails {
+		marker := ""
+		if i == index {
+			marker = "  <- rewrite this one"
+		}
+		sb.WriteString(fmt.Sprintf("- Email %d (day %d): %s%s\n", email.Number, email.DayDelay, email.Subject, marker))
+	}
+
+	if index > 0 {
+		writeNeighbour(&sb, "PREVIOUS EMAIL", j.Emails[index-1])
+	}
+	writeNeighbour(&sb, "CURRENT VERSION", target)
+	if index < len(j.Emails)-1 {
+		writeNeighbour(&sb, "NEXT EMAIL", j.Emails[index+1])
+	}
+
+	sb.WriteString(fmt.Sprintf("\nOUTPUT FORMAT (nothing else):\nEmail %d: <subject>\nSubject: <subject>\nPreview: <preview text>\nFramework: <framework>\n<body>\nCTA: <call to action>\n",
+		target.Number))
+	return sb.String()
+}
+
+func writeNeighbour(sb *strings.Builder, label string, email journey.Email) {
+	sb.WriteString(fmt.Sprintf("\n%s (Email %d):\nSubject: %s\n", label, email.Number, email.Subject))
+	if email.PreviewText != "" {
+		sb.WriteString(fmt.Sprintf("Preview: %s\n", email.PreviewText))
+	}
+	if email.Framework != "" {
+		sb.WriteString(fmt.Sprintf("Framework: %s\n", email.Framework))
+	}
+	if email.Body != "" {
+		sb.WriteString(email.Body + "\n")
+	}
+	if email.CTA != "" {
+		sb.WriteString(fmt.Sprintf("CTA: %s\n", email.CTA))
+	}
+}
+
+// regenerateMessage is the user turn asking for the rewrite.
+func regenerateMessage(number int, instructions string) string {
+	msg := fmt.Sprintf("Rewrite email %d now.", number)
+	if instructions != "" {
+		msg += " Instructions: " + instructions
+	}
+	return msg
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return v
+		}
+	}
+	return ""
+}
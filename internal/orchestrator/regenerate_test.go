@@ -0,0 +1,45 @@
+package orchestrator
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"JourneyBuilder/internal/journey"
+	"JourneyBuilder/internal/models"
+)
+
+func TestRegenerateEmailKeepsOrder(t *testing.T) {
+	o, llm := newTestOrchestrator(t)
+	j := &journey.Journey{Outcome: "First Purchase Acquisition", Emails: []journey.Email{
+		{Number: 3, Subject: "Third", Body: "Three", DayDelay: 5},
+		{Number: 1, Subject: "First", Body: "One"},
+		{Number: 2, Subject: "Second", Body: "Two", DayDelay: 2},
+	}}
+
+	resp, err := o.RegenerateEmail(context.Background(), &models.GenerateStepRequest{Journey: j, EmailNumber: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []string{"Third", "First", "A fresh take on your first step"} {
+		email := resp.Journey.Emails[i]
+		if email.Subject != want {
+			t.Errorf("email at %d is %d %q, want %q", i, email.Number, email.Subject, want)
+		}
+	}
+	if resp.Email.Number != 2 || resp.Email.DayDelay != 2 {
+		t.Errorf("rewritten email = %+v, want number 2 on day 2", resp.Email)
+	}
+
+	// The prompt still walks the sequence in number order
+	prompt := llm.Requests()[0].SystemPrompt
+	for _, want := range []string{
+		"- Email 1 (day 0): First\n- Email 2 (day 2): Second  <- rewrite this one\n- Email 3 (day 5): Third\n",
+		"PREVIOUS EMAIL (Email 1)",
+		"NEXT EMAIL (Email 3)",
+	} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt does not contain %q:\n%s", want, prompt)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package orchestrator
+
+import (
+	"strings"
+
+	"JourneyBuilder/internal/journey"
+	"JourneyBuilder/internal/models"
+)
+
+// rowScanner picks completed sequence table rows out of a token stream.
+type rowScanner struct {
+	enabled bool
+	pending string // text after the last newline
+}
+
+// feed consumes a token batch and returns any rows whose line is now complete.
+func (rs *rowScanner) feed(text string) []models.SequenceRowPayload {
+	if !rs.enabled {
+		return nil
+	}
+	rs.pending += text
+	idx := strings.LastIndex(rs.pending, "\n")
+	if idx < 0 {
+		return nil
+	}
+	complete := rs.pending[:idx]
+	rs.pending = rs.pending[idx+1:]
+	return parseRows(complete)
+}
+
+// flush returns a row left on the final, unterminated line.
+func (rs *rowScanner) flush() []models.SequenceRowPayload {
+	if !rs.enabled {
+		return nil
+	}
+	rows := parseRows(rs.pending)
+	rs.pending = ""
+	return rows
+}
+
+func parseRows(text string) []models.SequenceRowPayload {
+	var rows []models.SequenceRowPayload
+	for _, line := range strings.Split(text, "\n") {
+		email, ok := journey.ParseRow(line)
+		if !ok {
+			continue
+		}
+		rows = append(rows, models.SequenceRowPayload{
+			EmailNumber: email.Number,
+			SubjectLine: email.Subject,
+			DayDelay:    email.DayDelay,
+		})
+	}
+	return rows
+}
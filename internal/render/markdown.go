@@ -0,0 +1,117 @@
+package render
+
+import (
+	"html"
+	"html/template"
+	"regexp"
+	"strings"
+)
+
+var (
+	mdLink     = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	mdEmphasis = regexp.MustCompile(`\*\*(\S.*?)\*\*|__(\S.*?)__|\*(\S[^*]*?)\*|` + "`([^`]+)`")
+	mdHeading  = regexp.MustCompile(`(?m)^#{1,6}[ \t]+`)
+	mdBullet   = regexp.MustCompile(`(?m)^[ \t]*[*+-][ \t]+`)
+)
+
+// PlainText strips the markdown generated bodies use: headings, emphasis
+// and links, which keep their URL in parentheses. Bullets become dashes.
+// Single underscores are left alone so merge tags like {{first_name}}
+// survive.
+func PlainText(body string) string {
+	s := mdHeading.ReplaceAllString(body, "")
+	s = mdBullet.ReplaceAllString(s, "- ")
+	s = mdLink.ReplaceAllString(s, "$1 ($2)")
+	s = mdEmphasis.ReplaceAllString(s, "${1}${2}${3}${4}")
+	return strings.TrimSpace(s)
+}
+
+// block is a paragraph, heading or list of a body.
+type block struct {
+	heading bool
+	list    bool
+	lines   []string
+}
+
+// blocks splits a body on blank lines, and wherever a list starts or ends.
+func blocks(body string) []block {
+	var out []block
+	var cur *block
+	for _, line := range strings.Split(strings.ReplaceAll(body, "\r\n", "\n"), "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+			cur = nil
+		case mdHeading.MatchString(line):
+			out = append(out, block{heading: true, lines: []string{mdHeading.ReplaceAllString(line, "")}})
+			cur = nil
+		default:
+			item := mdBullet.MatchString(line)
+			if item {
+				line = mdBullet.ReplaceAllString(line, "")
+			}
+			if cur == nil || cur.list != item {
+				out = append(out, block{list: item})
+				cur = &out[len(out)-1]
+			}
+			cur.lines = append(cur.lines, line)
+		}
+	}
+	return out
+}
+
+// inlineHTML escapes s and converts its emphasis and links. Links other
+// than http(s) and mailto keep only their text.
+func inlineHTML(s string) string {
+	s = mdEmphasis.ReplaceAllStringFunc(html.EscapeString(s), func(m string) string {
+		parts := mdEmphasis.FindStringSubmatch(m)
+		switch {
+		case parts[1] != "" || parts[2] != "":
+			return "<strong>" + parts[1] + parts[2] + "</strong>"
+		case parts[3] != "":
+			return "<em>" + parts[3] + "</em>"
+		default:
+			return "<code>" + parts[4] + "</code>"
+		}
+	})
+	return mdLink.ReplaceAllStringFunc(s, func(m string) string {
+		parts := mdLink.FindStringSubmatch(m)
+		if !safeURL(html.UnescapeString(parts[2])) {
+			return parts[1]
+		}
+		return `<a href="` + parts[2] + `" style="` + linkStyle + `">` + parts[1] + `</a>`
+	})
+}
+
+// bodyHTML converts a markdown body into inline-styled HTML.
+func bodyHTML(body string) template.HTML {
+	var sb strings.Builder
+	for _, b := range blocks(body) {
+		switch {
+		case b.heading:
+			sb.WriteString(`<h2 style="` + headingStyle + `">` + inlineHTML(b.lines[0]) + "</h2>\n")
+		case b.list:
+			sb.WriteString(`<ul style="` + listStyle + `">` + "\n")
+			for _, line := range b.lines {
+				sb.WriteString(`<li style="` + itemStyle + `">` + inlineHTML(line) + "</li>\n")
+			}
+			sb.WriteString("</ul>\n")
+		default:
+			lines := make([]string, len(b.lines))
+			for i, line := range b.lines {
+				lines[i] = inlineHTML(line)
+			}
+			sb.WriteString(`<p style="` + paragraphStyle + `">` + strings.Join(lines, "<br>\n") + "</p>\n")
+		}
+	}
+	return template.HTML(sb.String())
+}
+
+// Inline styles for body elements; email clients drop <style> rules.
+const (
+	headingStyle   = "margin:0 0 16px;font-size:20px;line-height:28px;font-weight:bold;color:#111111;"
+	paragraphStyle = "margin:0 0 16px;font-size:16px;line-height:24px;color:#333333;"
+	listStyle      = "margin:0 0 16px;padding:0 0 0 24px;font-size:16px;line-height:24px;color:#333333;"
+	itemStyle      = "margin:0 0 8px;"
+	linkStyle      = "color:#1a73e8;text-decoration:underline;"
+)
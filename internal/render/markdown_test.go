@@ -0,0 +1,11 @@
+package render
+
+import "testing"
+
+func TestPlainText(t *testing.T) {
+	got := PlainText("## Hi {{first_name}}\n\n**Your cart** is *waiting*.\n* [Return to cart](https://example.com/cart)")
+	want := "Hi {{first_name}}\n\nYour cart is waiting.\n- Return to cart (https://example.com/cart)"
+	if got != want {
+		t.Errorf("PlainText = %q, want %q", got, want)
+	}
+}
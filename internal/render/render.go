@@ -0,0 +1,211 @@
+// Package render turns journey emails into the HTML and plain-text parts
+// an email client displays, with the preheader, call-to-action button and
+// compliance footer every send needs.
+package render
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	texttemplate "text/template"
+
+	"JourneyBuilder/internal/journey"
+)
+
+//go:embed templates/email.html templates/email.txt
+var templateFiles embed.FS
+
+var (
+	htmlLayout = template.Must(template.ParseFS(templateFiles, "templates/email.html"))
+	textLayout = texttemplate.Must(texttemplate.ParseFS(templateFiles, "templates/email.txt"))
+)
+
+// ErrNotCompliant is returned when the footer details CAN-SPAM requires in
+// every email are missing or unusable.
+var ErrNotCompliant = errors.New("email footer is not compliant")
+
+// defaultAccent is the CTA button colour when Options.AccentColor is empty.
+const defaultAccent = "#1a73e8"
+
+var hexColor = regexp.MustCompile(`^#(?:[0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// Options are the sender details shared by every email of a journey.
+type Options struct {
+	CompanyName     string // shown above the address in the footer
+	PhysicalAddress string // required
+	UnsubscribeURL  string // required; http(s) or mailto
+	// CTAURL is where the CTA button links when the body has no link with
+	// the CTA's text; "#" when empty.
+	CTAURL      string
+	AccentColor string // CTA button colour as #rgb or #rrggbb
+}
+
+// OptionsFromEnv reads COMPANY_NAME, COMPANY_ADDRESS, UNSUBSCRIBE_URL,
+// CTA_URL and EMAIL_ACCENT_COLOR.
+func OptionsFromEnv() Options {
+	return Options{
+		CompanyName:     os.Getenv("COMPANY_NAME"),
+		PhysicalAddress: os.Getenv("COMPANY_ADDRESS"),
+		UnsubscribeURL:  os.Getenv("UNSUBSCRIBE_URL"),
+		CTAURL:          os.Getenv("CTA_URL"),
+		AccentColor:     os.Getenv("EMAIL_ACCENT_COLOR"),
+	}
+}
+
+// Validate checks that the footer can carry a physical address and a
+// working unsubscribe link.
+func (o Options) Validate() error {
+	if strings.TrimSpace(o.PhysicalAddress) == "" {
+		return fmt.Errorf("%w: a physical address is required (set COMPANY_ADDRESS)", ErrNotCompliant)
+	}
+	if o.UnsubscribeURL == "" {
+		return fmt.Errorf("%w: an unsubscribe URL is required (set UNSUBSCRIBE_URL)", ErrNotCompliant)
+	}
+	if !safeURL(o.UnsubscribeURL) {
+		return fmt.Errorf("%w: unsubscribe URL %q must be an http(s) or mailto URL", ErrNotCompliant, o.UnsubscribeURL)
+	}
+	if o.CTAURL != "" && !safeURL(o.CTAURL) {
+		return fmt.Errorf("CTA URL %q must be an http(s) or mailto URL", o.CTAURL)
+	}
+	if o.AccentColor != "" && !hexColor.MatchString(o.AccentColor) {
+		return fmt.Errorf("accent color %q must be #rgb or #rrggbb", o.AccentColor)
+	}
+	return nil
+}
+
+// Email is a rendered journey email.
+type Email struct {
+	Number    int
+	Subject   string
+	Preheader string
+	HTML      string
+	Text      string
+}
+
+// RenderJourney renders every email of j, ordered by number.
+func RenderJourney(j *journey.Journey, opts Options) ([]Email, error) {
+	if err := j.Validate(); err != nil {
+		return nil, err
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	sorted := *j
+	sorted.Emails = append([]journey.Email(nil), j.Emails...)
+	sorted.SortEmails()
+
+	rendered := make([]Email, 0, len(sorted.Emails))
+	for _, e := range sorted.Emails {
+		r, err := Render(e, opts)
+		if err != nil {
+			return nil, err
+		}
+		rendered = append(rendered, r)
+	}
+	return rendered, nil
+}
+
+// Render lays out one email: its markdown body as inline-styled HTML in a
+// single responsive column, the preview text as a hidden preheader, a
+// button for the CTA, and the compliance footer.
+func Render(e journey.Email, opts Options) (Email, error) {
+	if err := opts.Validate(); err != nil {
+		return Email{}, err
+	}
+	data := layoutData{
+		Subject:         e.Subject,
+		Preheader:       e.PreviewText,
+		CTA:             e.CTA,
+		CTAURL:          ctaURL(e, opts),
+		Accent:          opts.AccentColor,
+		CompanyName:     opts.CompanyName,
+		PhysicalAddress: opts.PhysicalAddress,
+		UnsubscribeURL:  opts.UnsubscribeURL,
+	}
+	if data.Accent == "" {
+		data.Accent = defaultAccent
+	}
+
+	body := e.Body
+	if e.CTA != "" {
+		body = withoutClosingLink(body, data.CTAURL)
+	}
+
+	var htmlPart, textPart bytes.Buffer
+	data.Body = bodyHTML(body)
+	if err := htmlLayout.Execute(&htmlPart, data); err != nil {
+		return Email{}, fmt.Errorf("failed to render email %d: %w", e.Number, err)
+	}
+	data.Body = PlainText(body)
+	if err := textLayout.Execute(&textPart, data); err != nil {
+		return Email{}, fmt.Errorf("failed to render email %d: %w", e.Number, err)
+	}
+	return Email{
+		Number:    e.Number,
+		Subject:   e.Subject,
+		Preheader: e.PreviewText,
+		HTML:      htmlPart.String(),
+		Text:      textPart.String(),
+	}, nil
+}
+
+// layoutData feeds both layouts; Body is template.HTML for the HTML layout
+// and a string for the text one.
+type layoutData struct {
+	Subject, Preheader string
+	Body               any
+	CTA, CTAURL        string
+	Accent             string
+	CompanyName        string
+	PhysicalAddress    string
+	UnsubscribeURL     string
+}
+
+// ctaURL is the target of the body link whose text is the CTA, if any,
+// else opts.CTAURL.
+func ctaURL(e journey.Email, opts Options) string {
+	if e.CTA != "" {
+		for _, m := range mdLink.FindAllStringSubmatch(e.Body, -1) {
+			if strings.EqualFold(strings.TrimSpace(m[1]), e.CTA) && safeURL(m[2]) {
+				return m[2]
+			}
+		}
+	}
+	if opts.CTAURL != "" {
+		return opts.CTAURL
+	}
+	return "#"
+}
+
+// withoutClosingLink drops the last line of body if it is nothing but a
+// link to target, which the CTA button replaces.
+func withoutClosingLink(body, target string) string {
+	body = strings.TrimSpace(body)
+	i := strings.LastIndex(body, "\n")
+	last := strings.TrimSpace(body[i+1:])
+	if m := mdLink.FindStringSubmatch(last); m != nil && m[0] == last && m[2] == target {
+		return strings.TrimSpace(body[:max(i, 0)])
+	}
+	return body
+}
+
+// safeURL reports whether s is an absolute http(s) or mailto URL.
+func safeURL(s string) bool {
+	u, err := url.Parse(s)
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https":
+		return u.Host != ""
+	case "mailto":
+		return u.Opaque != ""
+	}
+	return false
+}
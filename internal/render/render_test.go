@@ -0,0 +1,172 @@
+package render
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"JourneyBuilder/internal/journey"
+)
+
+var testOptions = Options{
+	CompanyName:     "Acme",
+	PhysicalAddress: "1 Main St, Springfield",
+	UnsubscribeURL:  "https://acme.example/unsubscribe",
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name         string
+		edit         func(*Options)
+		ok           bool
+		notCompliant bool // the error wraps ErrNotCompliant
+	}{
+		{"complete", func(o *Options) {}, true, false},
+		{"mailto unsubscribe", func(o *Options) { o.UnsubscribeURL = "mailto:leave@acme.example" }, true, false},
+		{"no address", func(o *Options) { o.PhysicalAddress = "  " }, false, true},
+		{"no unsubscribe", func(o *Options) { o.UnsubscribeURL = "" }, false, true},
+		{"relative unsubscribe", func(o *Options) { o.UnsubscribeURL = "/unsubscribe" }, false, true},
+		{"script unsubscribe", func(o *Options) { o.UnsubscribeURL = "javascript:alert(1)" }, false, true},
+		{"bad CTA URL", func(o *Options) { o.CTAURL = "ftp://acme.example" }, false, false},
+		{"bad accent", func(o *Options) { o.AccentColor = "red" }, false, false},
+	}
+	for _, tt := range tests {
+		opts := testOptions
+		tt.edit(&opts)
+		err := opts.Validate()
+		if (err == nil) != tt.ok || errors.Is(err, ErrNotCompliant) != tt.notCompliant {
+			t.Errorf("%s: Validate() = %v", tt.name, err)
+		}
+	}
+}
+
+func TestRenderNotCompliant(t *testing.T) {
+	opts := testOptions
+	opts.UnsubscribeURL = ""
+	j := &journey.Journey{Emails: []journey.Email{{Number: 1, Subject: "Hi", Body: "Hello"}}}
+	if _, err := RenderJourney(j, opts); !errors.Is(err, ErrNotCompliant) {
+		t.Errorf("RenderJourney without an unsubscribe URL: err = %v, want ErrNotCompliant", err)
+	}
+	if _, err := Render(j.Emails[0], Options{UnsubscribeURL: testOptions.UnsubscribeURL}); !errors.Is(err, ErrNotCompliant) {
+		t.Errorf("Render without an address: err = %v, want ErrNotCompliant", err)
+	}
+}
+
+func TestRenderPreheader(t *testing.T) {
+	e := journey.Email{Number: 1, Subject: "Your cart", PreviewText: "Only 2 left in stock", Body: "Hello"}
+	r, err := Render(e, testOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(r.HTML, `opacity:0;">Only 2 left in stock&#847;`) {
+		t.Errorf("HTML has no hidden preheader:\n%s", r.HTML)
+	}
+	if r.Preheader != e.PreviewText {
+		t.Errorf("Preheader = %q", r.Preheader)
+	}
+	if strings.Contains(r.Text, e.PreviewText) {
+		t.Error("the text part repeats the preheader")
+	}
+
+	e.PreviewText = ""
+	if r, err = Render(e, testOptions); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(r.HTML, "display:none") {
+		t.Error("HTML has a preheader although the email has no preview text")
+	}
+}
+
+func TestCTAURL(t *testing.T) {
+	tests := []struct {
+		name   string
+		email  journey.Email
+		optURL string
+		want   string
+	}{
+		{"body link", journey.Email{CTA: "Return to cart", Body: "Hi\n\n[return to cart ](https://acme.example/cart)"}, "https://acme.example", "https://acme.example/cart"},
+		{"first matching link", journey.Email{CTA: "Shop", Body: "[Shop](https://a.example) or [Shop](https://b.example)"}, "", "https://a.example"},
+		{"unsafe link", journey.Email{CTA: "Shop", Body: "[Shop](javascript:alert(1))"}, "https://acme.example", "https://acme.example"},
+		{"other link", journey.Email{CTA: "Shop", Body: "[Blog](https://acme.example/blog)"}, "https://acme.example/shop", "https://acme.example/shop"},
+		{"no CTA", journey.Email{Body: "[Shop](https://acme.example/shop)"}, "https://acme.example", "https://acme.example"},
+		{"nothing", journey.Email{CTA: "Shop", Body: "Hello"}, "", "#"},
+	}
+	for _, tt := range tests {
+		if got := ctaURL(tt.email, Options{CTAURL: tt.optURL}); got != tt.want {
+			t.Errorf("%s: ctaURL = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestWithoutClosingLink(t *testing.T) {
+	const target = "https://acme.example/cart"
+	tests := []struct {
+		body, want string
+	}{
+		{"Hi\n\n[Return to cart](https://acme.example/cart)\n", "Hi"},
+		{"[Return to cart](https://acme.example/cart)", ""},
+		{"Hi\n\n[Read the blog](https://acme.example/blog)", "Hi\n\n[Read the blog](https://acme.example/blog)"},
+		{"Hi\n\nOr [return to cart](https://acme.example/cart) now", "Hi\n\nOr [return to cart](https://acme.example/cart) now"},
+		{"[Return to cart](https://acme.example/cart)\n\nSee you soon", "[Return to cart](https://acme.example/cart)\n\nSee you soon"},
+	}
+	for _, tt := range tests {
+		if got := withoutClosingLink(tt.body, target); got != tt.want {
+			t.Errorf("withoutClosingLink(%q) = %q, want %q", tt.body, got, tt.want)
+		}
+	}
+}
+
+func TestRenderCTA(t *testing.T) {
+	e := journey.Email{Number: 1, Subject: "Your cart", CTA: "Return to cart",
+		Body: "Still deciding?\n\n[Return to cart](https://acme.example/cart)"}
+	r, err := Render(e, testOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The closing link becomes the button, so the URL appears once
+	if n := strings.Count(r.HTML, `href="https://acme.example/cart"`); n != 1 {
+		t.Errorf("HTML links the CTA %d times, want once:\n%s", n, r.HTML)
+	}
+	if !strings.Contains(r.HTML, "background-color:"+defaultAccent) {
+		t.Error("button does not use the default accent colour")
+	}
+	if !strings.Contains(r.Text, "Return to cart: https://acme.example/cart") {
+		t.Errorf("text part has no CTA line:\n%s", r.Text)
+	}
+	if !strings.HasSuffix(r.Text, "1 Main St, Springfield\nUnsubscribe: https://acme.example/unsubscribe\n") {
+		t.Errorf("text part has no footer:\n%s", r.Text)
+	}
+}
+
+func TestRenderEscapes(t *testing.T) {
+	e := journey.Email{
+		Number:      1,
+		Subject:     `Save <b>50%</b> & "more"`,
+		PreviewText: "<script>alert(1)</script>",
+		Body:        "Tom & Jerry <img src=x onerror=alert(1)>\n\n[click](javascript:alert(1))",
+		CTA:         `<Go>`,
+	}
+	r, err := Render(e, testOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, bad := range []string{"<b>50%", "<script>", "<img", "javascript:", "<Go>"} {
+		if strings.Contains(r.HTML, bad) {
+			t.Errorf("HTML contains unescaped %q", bad)
+		}
+	}
+	for _, want := range []string{
+		"<title>Save &lt;b&gt;50%&lt;/b&gt; &amp; &#34;more&#34;</title>",
+		"&lt;script&gt;alert(1)&lt;/script&gt;",
+		"Tom &amp; Jerry &lt;img src=x onerror=alert(1)&gt;",
+		"&lt;Go&gt;</a>",
+	} {
+		if !strings.Contains(r.HTML, want) {
+			t.Errorf("HTML does not contain %q", want)
+		}
+	}
+	// The text part is not HTML and keeps the copy as written
+	if !strings.Contains(r.Text, "Tom & Jerry <img src=x onerror=alert(1)>") {
+		t.Errorf("text part changed the body:\n%s", r.Text)
+	}
+}
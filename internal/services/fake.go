@@ -0,0 +1,219 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// fakeDefaultKey is the fallback response key used when no step-specific response exists.
+const fakeDefaultKey = "default"
+
+// defaultFakeResponses are canned replies for each workflow step.
+// They contain the phrases ContextBuilder looks for, so a scripted conversation
+// advances through all 8 steps just like it would against a real model.
+var defaultFakeResponses = map[string]string{
+	"StepIntroduction": "Hi, I'm Da Vinci, The Automated Email Sequence Creator. Let me ask you a few questions to get started.\n\n" +
+		"Tell me about your product's Unique Selling Proposition (USP) and its Ideal Customer Profile (ICP).",
+	"StepDiscovery": "Tell me about your product's Unique Selling Proposition (USP) and its Ideal Customer Profile (ICP).",
+	"StepValidation": "Let me summarize my understanding of your USP and ICP.\n\n" +
+		"**USP:** A clearly differentiated offer your competitors can't match.\n" +
+		"**ICP:** Busy buyers who already feel the problem you solve.\n\n" +
+		"Can you confirm this is correct?",
+	"StepFrameworkApplication": "Who is your intended audience according to The Buyers' Circles of Trust(tm)? " +
+		"If you're not sure, tell me who you want to target and I'll identify which Circle of Trust it is.",
+	"StepCircleConfirmation": "Based on what you've shared, your intended audience is the Follower circle of trust. " +
+		"Can you confirm this is correct?",
+	"StepGoalSetting": "What is the desired outcome of your automated email sequence?",
+	"StepAnalysis": "Analysis: The desired outcome is highly appropriate for this Circle of Trust. " +
+		"It aligns with where these subscribers are in their relationship with your brand.",
+	"StepExecution": "| Email # | Subject Line | Day Delay |\n" +
+		"| --- | --- | --- |\n" +
+		"| 1 | Welcome, here's your first step | 0 |\n" +
+		"| 2 | What customers say after week one | 2 |\n" +
+		"| 3 | Your offer ends soon | 5 |\n\n" +
+		"Email 1: Welcome, here's your first step\n" +
+		"Subject: Welcome, here's your first step\n" +
+		"Preview: A quick start so you see results fast.\n" +
+		"Framework: AIDA\n" +
+		"Thanks for joining us. Here is the one thing to do today to get value right away.\n" +
+		"CTA: Get started\n\n" +
+		"Email 2: What customers say after week one\n" +
+		"Subject: What customers say after week one\n" +
+		"Preview: Real stories from people like you.\n" +
+		"Framework: FAB\n" +
+		"Hundreds of customers made the switch. Here is what changed for them in the first week.\n" +
+		"CTA: Read their stories\n\n" +
+		"Email 3: Your offer ends soon\n" +
+		"Subject: Your offer ends soon\n" +
+		"Preview: Last chance to claim your welcome offer.\n" +
+		"Framework: 4Ps\n" +
+		"Your welcome offer expires in 48 hours. Claim it now and start seeing results.\n" +
+		"CTA: Claim my offer\n",
+	"StepPreview": "| Email # | Subject Line | Day Delay | Framework |\n" +
+		"| --- | --- | --- | --- |\n" +
+		"| 1 | Welcome, here's your first step | 0 | AIDA |\n" +
+		"| 2 | What customers say after week one | 2 | FAB |\n" +
+		"| 3 | Your offer ends soon | 5 | 4Ps |\n" +
+		"| 4 | Still deciding? Read this | 7 | FAB |\n" +
+		"| 5 | Last call for your welcome offer | 10 | 4Ps |\n",
+	"StepRegenerateEmail": "Email 1: A fresh take on your first step\n" +
+		"Subject: A fresh take on your first step\n" +
+		"Preview: Shorter, clearer, and ready when you are.\n" +
+		"Framework: AIDA\n" +
+		"Here is the one thing to do today. It takes two minutes and you will see the difference tomorrow.\n" +
+		"CTA: Take the first step\n",
+	fakeDefaultKey: "This is a scripted response from the fake LLM provider.",
+}
+
+// FakeProvider is a deterministic, offline LLMProvider for development and tests.
+// It returns canned responses keyed by RequestBuilder.WorkflowStep and records
+// every request it receives.
+type FakeProvider struct {
+	mu        sync.Mutex
+	responses map[string]string // workflow step → canned response
+	requests  []RequestBuilder
+}
+
+var _ LLMProvider = (*FakeProvider)(nil)
+
+// NewFakeProvider creates a fake provider. Entries in responses override the
+// built-in defaults for the matching workflow step.
+func NewFakeProvider(responses map[string]string) *FakeProvider {
+	f := &FakeProvider{
+		responses: make(map[string]string, len(defaultFakeResponses)),
+	}
+	for step, text := range defaultFakeResponses {
+		f.responses[step] = text
+	}
+	for step, text := range responses {
+		f.responses[step] = text
+	}
+	return f
+}
+
+// NewFakeProviderFromDir loads canned responses from a fixtures directory.
+// Each file is named after a workflow step (e.g. "StepExecution.md" or
+// "StepExecution.txt"); "default.txt" overrides the fallback response.
+func NewFakeProviderFromDir(dir string) (*FakeProvider, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fake LLM fixtures: %w", err)
+	}
+
+	responses := make(map[string]string)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read fixture %s: %w", name, err)
+		}
+		step := strings.TrimSuffix(name, filepath.Ext(name))
+		responses[step] = string(data)
+	}
+
+	return NewFakeProvider(responses), nil
+}
+
+// NewFakeProviderFromEnv loads fixtures from FAKE_LLM_FIXTURES if set,
+// otherwise it uses the built-in responses.
+func NewFakeProviderFromEnv() (*FakeProvider, error) {
+	if dir := os.Getenv("FAKE_LLM_FIXTURES"); dir != "" {
+		return NewFakeProviderFromDir(dir)
+	}
+	return NewFakeProvider(nil), nil
+}
+
+// SetResponse replaces the canned response for a workflow step.
+func (f *FakeProvider) SetResponse(step, text string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.responses[step] = text
+}
+
+// Requests returns a copy of every request received so far, in order.
+func (f *FakeProvider) Requests() []RequestBuilder {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	out := make([]RequestBuilder, len(f.requests))
+	copy(out, f.requests)
+	return out
+}
+
+// Reset forgets all recorded requests.
+func (f *FakeProvider) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = nil
+}
+
+// SendRequest records the request and returns the canned response for its step.
+func (f *FakeProvider) SendRequest(ctx context.Context, req *RequestBuilder) (*Response, error) {
+	if err := ctx.Err(); err != nil {
+		return &Response{Error: err.Error()}, err
+	}
+	return &Response{Text: f.respond(req)}, nil
+}
+
+// StreamRequest records the request and emits the canned response word by word.
+func (f *FakeProvider) StreamRequest(ctx context.Context, req *RequestBuilder) (<-chan StreamChunk, error) {
+	text := f.respond(req)
+	out := make(chan StreamChunk)
+
+	go func() {
+		defer close(out)
+		for _, chunk := range splitKeepingSpace(text) {
+			if !sendChunk(ctx, out, StreamChunk{Text: chunk}) {
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// Close is a no-op.
+func (f *FakeProvider) Close() error {
+	return nil
+}
+
+func (f *FakeProvider) respond(req *RequestBuilder) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	recorded := *req
+	recorded.ConversationHistory = append([]Message(nil), req.ConversationHistory...)
+	f.requests = append(f.requests, recorded)
+
+	if text, ok := f.responses[req.WorkflowStep]; ok {
+		return text
+	}
+	return f.responses[fakeDefaultKey]
+}
+
+// splitKeepingSpace splits text after each run of whitespace so that joining
+// the pieces reproduces the original text exactly.
+func splitKeepingSpace(text string) []string {
+	var chunks []string
+	start := 0
+	for i := 1; i < len(text); i++ {
+		if isSpace(text[i-1]) && !isSpace(text[i]) {
+			chunks = append(chunks, text[start:i])
+			start = i
+		}
+	}
+	if start < len(text) {
+		chunks = append(chunks, text[start:])
+	}
+	return chunks
+}
+
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\n' || b == '\t' || b == '\r'
+}
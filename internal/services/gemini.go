@@ -13,7 +13,8 @@ import (
 	"google.golang.org/genai"
 )
 
-// Client is a wrapper around Gemini GenerativeModel.
+// GeminiService is a wrapper around Gemini GenerativeModel.
+// It implements LLMProvider.
 type GeminiService struct {
 	client *genai.Client
 	model  string
@@ -25,13 +26,14 @@ type Message struct {
 	Content string
 }
 
-// RequestBuilder configures the Gemini AI request.
+// RequestBuilder configures an LLM request.
 type RequestBuilder struct {
 	SystemPrompt        string
 	UserMessage         string
 	ConversationHistory []Message
 	Temperature         float32
 	MaxTokens           int
+	WorkflowStep        string // optional label, e.g. "StepExecution"; used by scripted providers
 }
 
 // Response contains the model output.
@@ -78,6 +80,8 @@ func NewGeminiService() (*GeminiService, error) {
 	}, nil
 }
 
+var _ LLMProvider = (*GeminiService)(nil)
+
 // Close closes the underlying genai client connection.
 // Note: genai.Client may not have a Close method, so this is a no-op for now.
 func (c *GeminiService) Close() error {
@@ -88,6 +92,92 @@ func (c *GeminiService) Close() error {
 
 // SendRequest sends a request to Gemini.
 func (c *GeminiService) SendRequest(ctx context.Context, req *RequestBuilder) (*Response, error) {
+	contents, config := c.buildContents(req)
+
+	// Generate content with retry logic for transient errors
+	var resp *genai.GenerateContentResponse
+	var err error
+	maxRetries := 3
+	retryDelay := time.Second
+
+	for attempt := 0; attempt < maxRetries; attempt++ {
+		resp, err = c.client.Models.GenerateContent(ctx, c.model, contents, config)
+		if err == nil {
+			break
+		}
+
+		// Check if it's a retryable error (503, 429, or temporary errors)
+		if !isRetryableError(err) || attempt == maxRetries-1 {
+			return &Response{Error: fmt.Sprintf("Failed to generate the content: %v", err)}, err
+		}
+
+		// Wait before retrying with exponential backoff
+		time.Sleep(retryDelay * time.Duration(attempt+1))
+	}
+
+	if err != nil {
+		return &Response{Error: fmt.Sprintf("Failed to generate the content after %d attempts: %v", maxRetries, err)}, err
+	}
+
+	// Extract text from response
+	if len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil || len(resp.Candidates[0].Content.Parts) == 0 {
+		return &Response{Text: ""}, nil
+	}
+
+	responseText := resp.Candidates[0].Content.Parts[0].Text
+	logger.Printf("🤖 MODEL RESPONSE:\n%s\n", responseText)
+	return &Response{Text: responseText}, nil
+}
+
+// StreamRequest streams a request to Gemini, forwarding each token batch as it arrives.
+// Transient errors are retried only until the first chunk has been sent.
+func (c *GeminiService) StreamRequest(ctx context.Context, req *RequestBuilder) (<-chan StreamChunk, error) {
+	contents, config := c.buildContents(req)
+	out := make(chan StreamChunk)
+
+	go func() {
+		defer close(out)
+
+		var full strings.Builder
+		maxRetries := 3
+		retryDelay := time.Second
+
+		for attempt := 0; attempt < maxRetries; attempt++ {
+			var streamErr error
+			for resp, err := range c.client.Models.GenerateContentStream(ctx, c.model, contents, config) {
+				if err != nil {
+					streamErr = err
+					break
+				}
+				text := resp.Text()
+				if text == "" {
+					continue
+				}
+				full.WriteString(text)
+				if !sendChunk(ctx, out, StreamChunk{Text: text}) {
+					return
+				}
+			}
+
+			if streamErr == nil {
+				logger.Printf("🤖 MODEL RESPONSE (streamed):\n%s\n", full.String())
+				return
+			}
+			if full.Len() > 0 || !isRetryableError(streamErr) || attempt == maxRetries-1 {
+				sendChunk(ctx, out, StreamChunk{Err: fmt.Errorf("failed to stream the content: %w", streamErr)})
+				return
+			}
+
+			// Wait before retrying with exponential backoff
+			time.Sleep(retryDelay * time.Duration(attempt+1))
+		}
+	}()
+
+	return out, nil
+}
+
+// buildContents converts a RequestBuilder into Gemini contents and generation config.
+func (c *GeminiService) buildContents(req *RequestBuilder) ([]*genai.Content, *genai.GenerateContentConfig) {
 	// Build content parts
 	var contents []*genai.Content
 
@@ -138,48 +228,5 @@ func (c *GeminiService) SendRequest(ctx context.Context, req *RequestBuilder) (*
 		config.MaxOutputTokens = m
 	}
 
-	// Generate content with retry logic for transient errors
-	var resp *genai.GenerateContentResponse
-	var err error
-	maxRetries := 3
-	retryDelay := time.Second
-
-	for attempt := 0; attempt < maxRetries; attempt++ {
-		resp, err = c.client.Models.GenerateContent(ctx, c.model, contents, config)
-		if err == nil {
-			break
-		}
-
-		// Check if it's a retryable error (503, 429, or temporary errors)
-		errStr := strings.ToLower(err.Error())
-		isRetryable := false
-		if errStr != "" {
-			// Check for common retryable error patterns
-			if strings.Contains(errStr, "503") || strings.Contains(errStr, "overloaded") ||
-				strings.Contains(errStr, "429") || strings.Contains(errStr, "rate limit") ||
-				strings.Contains(errStr, "unavailable") || strings.Contains(errStr, "resource_exhausted") {
-				isRetryable = true
-			}
-		}
-
-		if !isRetryable || attempt == maxRetries-1 {
-			return &Response{Error: fmt.Sprintf("Failed to generate the content: %v", err)}, err
-		}
-
-		// Wait before retrying with exponential backoff
-		time.Sleep(retryDelay * time.Duration(attempt+1))
-	}
-
-	if err != nil {
-		return &Response{Error: fmt.Sprintf("Failed to generate the content after %d attempts: %v", maxRetries, err)}, err
-	}
-
-	// Extract text from response
-	if len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil || len(resp.Candidates[0].Content.Parts) == 0 {
-		return &Response{Text: ""}, nil
-	}
-
-	responseText := resp.Candidates[0].Content.Parts[0].Text
-	logger.Printf("🤖 MODEL RESPONSE:\n%s\n", responseText)
-	return &Response{Text: responseText}, nil
+	return contents, config
 }
@@ -0,0 +1,290 @@
+package services
+
+import (
+	"JourneyBuilder/internal/logger"
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// openAIRequestTimeout bounds one blocking completion attempt. Streams are
+// only bounded by the caller's context, since a long generation keeps
+// sending for as long as it runs.
+const openAIRequestTimeout = 5 * time.Minute
+
+// OpenAIService talks to any server exposing the OpenAI /v1/chat/completions API,
+// e.g. OpenAI itself, llama.cpp, vLLM or Ollama.
+// It implements LLMProvider.
+type OpenAIService struct {
+	httpClient *http.Client
+	baseURL    string
+	apiKey     string
+	model      string
+	retryDelay time.Duration
+}
+
+var _ LLMProvider = (*OpenAIService)(nil)
+
+// openAIMessage is a single message in the chat completions wire format.
+type openAIMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// openAIRequest is the chat completions request body.
+type openAIRequest struct {
+	Model       string          `json:"model"`
+	Messages    []openAIMessage `json:"messages"`
+	Temperature *float32        `json:"temperature,omitempty"`
+	MaxTokens   int             `json:"max_tokens,omitempty"`
+	Stream      bool            `json:"stream,omitempty"`
+}
+
+// openAIResponse covers both the blocking response and a streamed chunk.
+type openAIResponse struct {
+	Choices []struct {
+		Message openAIMessage `json:"message"`
+		Delta   openAIMessage `json:"delta"`
+	} `json:"choices"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// openAIStatusError is a non-2xx reply from the chat completions endpoint.
+type openAIStatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *openAIStatusError) Error() string {
+	return fmt.Sprintf("chat completions returned %d: %s", e.StatusCode, e.Body)
+}
+
+// NewOpenAIService configures the client from the environment:
+// OPENAI_BASE_URL (default https://api.openai.com/v1; use e.g. http://localhost:11434/v1 for Ollama),
+// OPENAI_API_KEY (optional for local servers) and OPENAI_MODEL (default gpt-4o-mini).
+func NewOpenAIService() (*OpenAIService, error) {
+	baseURL := strings.TrimRight(os.Getenv("OPENAI_BASE_URL"), "/")
+	if baseURL == "" {
+		baseURL = "https://api.openai.com/v1"
+	}
+	model := os.Getenv("OPENAI_MODEL")
+	if model == "" {
+		model = "gpt-4o-mini"
+	}
+
+	logger.Printf("✓ OpenAI-compatible client configured (%s, model %s)", baseURL, model)
+
+	return &OpenAIService{
+		httpClient: &http.Client{},
+		baseURL:    baseURL,
+		apiKey:     os.Getenv("OPENAI_API_KEY"),
+		model:      model,
+		retryDelay: time.Second,
+	}, nil
+}
+
+// Close is a no-op; the HTTP client holds no long-lived resources.
+func (c *OpenAIService) Close() error {
+	return nil
+}
+
+// SendRequest sends a blocking chat completion request, retrying rate limits
+// and server errors. Each attempt has its own deadline of openAIRequestTimeout.
+func (c *OpenAIService) SendRequest(ctx context.Context, req *RequestBuilder) (*Response, error) {
+	body := c.buildRequest(req, false)
+
+	var parsed *openAIResponse
+	var err error
+	maxRetries := 3
+
+	for attempt := 0; attempt < maxRetries; attempt++ {
+		parsed, err = c.complete(ctx, body)
+		if err == nil {
+			break
+		}
+		if !isRetryableOpenAIError(err) || attempt == maxRetries-1 {
+			return &Response{Error: fmt.Sprintf("Failed to generate the content: %v", err)}, err
+		}
+		// Wait before retrying, unless the caller gives up first
+		timer := time.NewTimer(c.retryDelay * time.Duration(attempt+1))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return &Response{Error: fmt.Sprintf("Failed to generate the content: %v", ctx.Err())}, ctx.Err()
+		}
+	}
+	if len(parsed.Choices) == 0 {
+		return &Response{Text: ""}, nil
+	}
+
+	responseText := parsed.Choices[0].Message.Content
+	logger.Printf("🤖 MODEL RESPONSE:\n%s\n", responseText)
+	return &Response{Text: responseText}, nil
+}
+
+// complete runs one blocking completion attempt under its own deadline.
+func (c *OpenAIService) complete(ctx context.Context, body *openAIRequest) (*openAIResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, openAIRequestTimeout)
+	defer cancel()
+
+	httpResp, err := c.post(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	var parsed openAIResponse
+	if err := json.NewDecoder(httpResp.Body).Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("failed to decode the response: %w", err)
+	}
+	return &parsed, nil
+}
+
+// isRetryableOpenAIError reports whether a failed attempt is worth repeating:
+// rate limits, server errors and transient transport failures.
+func isRetryableOpenAIError(err error) bool {
+	var statusErr *openAIStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusTooManyRequests || statusErr.StatusCode >= 500
+	}
+	return isRetryableError(err)
+}
+
+// StreamRequest sends a streaming chat completion request and forwards each delta.
+func (c *OpenAIService) StreamRequest(ctx context.Context, req *RequestBuilder) (<-chan StreamChunk, error) {
+	httpResp, err := c.post(ctx, c.buildRequest(req, true))
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan StreamChunk)
+	go func() {
+		defer close(out)
+		defer httpResp.Body.Close()
+
+		scanner := bufio.NewScanner(httpResp.Body)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if !strings.HasPrefix(line, "data:") {
+				continue
+			}
+			data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+			if data == "[DONE]" {
+				return
+			}
+
+			var chunk openAIResponse
+			if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+				sendChunk(ctx, out, StreamChunk{Err: fmt.Errorf("failed to decode stream chunk: %w", err)})
+				return
+			}
+			if chunk.Error != nil {
+				sendChunk(ctx, out, StreamChunk{Err: fmt.Errorf("stream error: %s", chunk.Error.Message)})
+				return
+			}
+			if len(chunk.Choices) == 0 || chunk.Choices[0].Delta.Content == "" {
+				continue
+			}
+			if !sendChunk(ctx, out, StreamChunk{Text: chunk.Choices[0].Delta.Content}) {
+				return
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			sendChunk(ctx, out, StreamChunk{Err: err})
+		}
+	}()
+
+	return out, nil
+}
+
+// buildRequest maps a RequestBuilder onto the chat completions format.
+func (c *OpenAIService) buildRequest(req *RequestBuilder, stream bool) *openAIRequest {
+	var messages []openAIMessage
+
+	if req.SystemPrompt != "" {
+		logger.Printf("📝 GENERATED SYSTEM PROMPT:\n%s\n", req.SystemPrompt)
+		messages = append(messages, openAIMessage{Role: "system", Content: req.SystemPrompt})
+	}
+
+	// Add conversation history
+	for _, msg := range req.ConversationHistory {
+		// Convert "ai"/"model" role to "assistant" as required by the OpenAI API
+		role := msg.Role
+		if role == "ai" || role == "model" {
+			role = "assistant"
+		}
+		// Ensure role is either "user" or "assistant"
+		if role != "user" && role != "assistant" {
+			role = "user" // Default to "user" if invalid
+		}
+		messages = append(messages, openAIMessage{Role: role, Content: msg.Content})
+	}
+
+	logger.Printf("👤 USER MESSAGE:\n%s\n", req.UserMessage)
+	messages = append(messages, openAIMessage{Role: "user", Content: req.UserMessage})
+
+	body := &openAIRequest{
+		Model:     c.model,
+		Messages:  messages,
+		MaxTokens: req.MaxTokens,
+		Stream:    stream,
+	}
+	if req.Temperature != 0 {
+		t := req.Temperature
+		body.Temperature = &t
+	}
+	return body
+}
+
+// post sends the request and returns the response if it has a 2xx status.
+func (c *OpenAIService) post(ctx context.Context, body *openAIRequest) (*http.Response, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/chat/completions", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if body.Stream {
+		httpReq.Header.Set("Accept", "text/event-stream")
+	}
+	if c.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, &openAIStatusError{StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(msg))}
+	}
+	return resp, nil
+}
+
+// sendChunk delivers a chunk unless the context is cancelled first.
+func sendChunk(ctx context.Context, out chan<- StreamChunk, chunk StreamChunk) bool {
+	select {
+	case out <- chunk:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,205 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// chatServer is a fake chat completions endpoint. Each call takes the next
+// handler in turn; the last one repeats.
+type chatServer struct {
+	mu       sync.Mutex
+	handlers []http.HandlerFunc
+	requests []openAIRequest
+}
+
+func newChatServer(t *testing.T, handlers ...http.HandlerFunc) (*chatServer, *OpenAIService) {
+	t.Helper()
+	s := &chatServer{handlers: handlers}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body openAIRequest
+		if r.URL.Path != "/v1/chat/completions" || json.NewDecoder(r.Body).Decode(&body) != nil {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		s.mu.Lock()
+		s.requests = append(s.requests, body)
+		h := s.handlers[min(len(s.requests), len(s.handlers))-1]
+		s.mu.Unlock()
+		h(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	return s, &OpenAIService{httpClient: srv.Client(), baseURL: srv.URL + "/v1", model: "test-model", retryDelay: time.Millisecond}
+}
+
+func (s *chatServer) calls() []openAIRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]openAIRequest(nil), s.requests...)
+}
+
+func reply(text string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"choices":[{"message":{"role":"assistant","content":%q}}]}`, text)
+	}
+}
+
+func status(code int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, http.StatusText(code), code)
+	}
+}
+
+func TestOpenAIRoles(t *testing.T) {
+	srv, c := newChatServer(t, reply("Hello"))
+	resp, err := c.SendRequest(context.Background(), &RequestBuilder{
+		SystemPrompt: "Be brief",
+		UserMessage:  "And now?",
+		ConversationHistory: []Message{
+			{Role: "user", Content: "Hi"},
+			{Role: "ai", Content: "Hey"},
+			{Role: "model", Content: "Still here"},
+			{Role: "tool", Content: "Odd"},
+		},
+		Temperature: 0.5,
+	})
+	if err != nil || resp.Text != "Hello" {
+		t.Fatalf("SendRequest = %+v, %v", resp, err)
+	}
+
+	calls := srv.calls()
+	if len(calls) != 1 {
+		t.Fatalf("server got %d requests, want 1", len(calls))
+	}
+	want := []openAIMessage{
+		{Role: "system", Content: "Be brief"},
+		{Role: "user", Content: "Hi"},
+		{Role: "assistant", Content: "Hey"},
+		{Role: "assistant", Content: "Still here"},
+		{Role: "user", Content: "Odd"},
+		{Role: "user", Content: "And now?"},
+	}
+	if !reflect.DeepEqual(calls[0].Messages, want) {
+		t.Errorf("messages = %+v, want %+v", calls[0].Messages, want)
+	}
+	if calls[0].Model != "test-model" || calls[0].Stream || calls[0].Temperature == nil || *calls[0].Temperature != 0.5 {
+		t.Errorf("request = %+v", calls[0])
+	}
+}
+
+func TestOpenAIRetries(t *testing.T) {
+	tests := []struct {
+		name     string
+		handlers []http.HandlerFunc
+		calls    int
+		ok       bool
+	}{
+		{"rate limited", []http.HandlerFunc{status(http.StatusTooManyRequests), reply("ok")}, 2, true},
+		{"server errors", []http.HandlerFunc{status(http.StatusInternalServerError), status(http.StatusBadGateway), reply("ok")}, 3, true},
+		{"always unavailable", []http.HandlerFunc{status(http.StatusServiceUnavailable)}, 3, false},
+		{"bad request", []http.HandlerFunc{status(http.StatusBadRequest), reply("ok")}, 1, false},
+	}
+	for _, tt := range tests {
+		srv, c := newChatServer(t, tt.handlers...)
+		resp, err := c.SendRequest(context.Background(), &RequestBuilder{UserMessage: "Hi"})
+		if n := len(srv.calls()); n != tt.calls {
+			t.Errorf("%s: server got %d requests, want %d", tt.name, n, tt.calls)
+		}
+		if tt.ok && (err != nil || resp.Text != "ok") {
+			t.Errorf("%s: SendRequest = %+v, %v", tt.name, resp, err)
+		}
+		if !tt.ok && (err == nil || resp.Error == "") {
+			t.Errorf("%s: SendRequest = %+v, want an error", tt.name, resp)
+		}
+	}
+}
+
+func TestOpenAIRetryCancelled(t *testing.T) {
+	srv, c := newChatServer(t, status(http.StatusTooManyRequests))
+	c.retryDelay = time.Hour
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := c.SendRequest(ctx, &RequestBuilder{UserMessage: "Hi"}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want the context's error", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("SendRequest waited %s after the context ended", elapsed)
+	}
+	if n := len(srv.calls()); n != 1 {
+		t.Errorf("server got %d requests, want 1", n)
+	}
+}
+
+func TestOpenAIStream(t *testing.T) {
+	srv, c := newChatServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, ": keep-alive\n\n")
+		fmt.Fprint(w, `data: {"choices":[{"delta":{"role":"assistant"}}]}`+"\n\n")
+		fmt.Fprint(w, `data: {"choices":[{"delta":{"content":"Hel"}}]}`+"\n\n")
+		w.(http.Flusher).Flush()
+		fmt.Fprint(w, `data:{"choices":[{"delta":{"content":"lo"}}]}`+"\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+		fmt.Fprint(w, `data: {"choices":[{"delta":{"content":" after done"}}]}`+"\n\n")
+	})
+	chunks, err := c.StreamRequest(context.Background(), &RequestBuilder{UserMessage: "Hi"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var text strings.Builder
+	for chunk := range chunks {
+		if chunk.Err != nil {
+			t.Fatalf("chunk error: %v", chunk.Err)
+		}
+		text.WriteString(chunk.Text)
+	}
+	if text.String() != "Hello" {
+		t.Errorf("streamed %q, want %q", text.String(), "Hello")
+	}
+	if calls := srv.calls(); len(calls) != 1 || !calls[0].Stream {
+		t.Errorf("requests = %+v, want one streaming request", calls)
+	}
+}
+
+func TestOpenAIStreamErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"error event", `data: {"error":{"message":"model overloaded"}}` + "\n\n", "model overloaded"},
+		{"bad chunk", "data: {not json\n\n", "failed to decode stream chunk"},
+	}
+	for _, tt := range tests {
+		_, c := newChatServer(t, func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `data: {"choices":[{"delta":{"content":"Hi"}}]}`+"\n\n"+tt.body)
+		})
+		chunks, err := c.StreamRequest(context.Background(), &RequestBuilder{UserMessage: "Hi"})
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		var got []StreamChunk
+		for chunk := range chunks {
+			got = append(got, chunk)
+		}
+		if len(got) != 2 || got[0].Text != "Hi" || got[1].Err == nil || !strings.Contains(got[1].Err.Error(), tt.want) {
+			t.Errorf("%s: chunks = %+v", tt.name, got)
+		}
+	}
+
+	// A failed status is reported before any chunk is read
+	_, c := newChatServer(t, status(http.StatusUnauthorized))
+	if _, err := c.StreamRequest(context.Background(), &RequestBuilder{UserMessage: "Hi"}); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("StreamRequest with 401: err = %v", err)
+	}
+}
@@ -0,0 +1,68 @@
+package services
+
+import (
+	"JourneyBuilder/internal/logger"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// LLMProvider is implemented by every model backend the orchestrator can talk to.
+type LLMProvider interface {
+	// SendRequest runs a blocking generation and returns the full response.
+	SendRequest(ctx context.Context, req *RequestBuilder) (*Response, error)
+	// StreamRequest runs a generation and emits text chunks as they arrive.
+	// The channel is closed once the generation finishes or fails.
+	StreamRequest(ctx context.Context, req *RequestBuilder) (<-chan StreamChunk, error)
+	// Close releases any resources held by the provider.
+	Close() error
+}
+
+// StreamChunk is a single piece of a streamed response.
+// A chunk with a non-nil Err is always the last one sent on the channel.
+type StreamChunk struct {
+	Text string
+	Err  error
+}
+
+// Provider names accepted by NewProvider and the LLM_PROVIDER env var.
+const (
+	ProviderGemini = "gemini"
+	ProviderOpenAI = "openai"
+	ProviderFake   = "fake"
+)
+
+// NewProviderFromEnv builds the provider selected by LLM_PROVIDER, which
+// defaults to "gemini". The offline "fake" provider is only used when asked
+// for, so a missing GCP_PROJECT_ID is reported rather than hidden.
+func NewProviderFromEnv() (LLMProvider, error) {
+	name := strings.ToLower(strings.TrimSpace(os.Getenv("LLM_PROVIDER")))
+	if name == "" {
+		name = ProviderGemini
+	}
+	logger.Printf("Using LLM provider: %s", name)
+	return NewProvider(name)
+}
+
+// NewProvider builds the named provider using its own env configuration.
+func NewProvider(name string) (LLMProvider, error) {
+	switch name {
+	case ProviderGemini:
+		return NewGeminiService()
+	case ProviderOpenAI:
+		return NewOpenAIService()
+	case ProviderFake:
+		return NewFakeProviderFromEnv()
+	default:
+		return nil, fmt.Errorf("unknown LLM provider %q", name)
+	}
+}
+
+// isRetryableError reports whether err looks transient (overload, rate limit, unavailable).
+func isRetryableError(err error) bool {
+	errStr := strings.ToLower(err.Error())
+	return strings.Contains(errStr, "503") || strings.Contains(errStr, "overloaded") ||
+		strings.Contains(errStr, "429") || strings.Contains(errStr, "rate limit") ||
+		strings.Contains(errStr, "unavailable") || strings.Contains(errStr, "resource_exhausted")
+}
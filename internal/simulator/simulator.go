@@ -0,0 +1,306 @@
+// Package simulator plays a journey against a scripted subscriber: given
+// events such as "opened on day 2, clicked on day 5" it reports which emails
+// would be sent and when, and where the journey's branches take over. It
+// needs nothing but the journey itself, so it runs offline.
+package simulator
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"JourneyBuilder/internal/branching"
+	"JourneyBuilder/internal/journey"
+)
+
+// ErrInvalidScript is returned when a scripted event cannot be simulated.
+var ErrInvalidScript = errors.New("invalid event script")
+
+// maxScriptDays bounds how far into the journey a scripted event may be, so
+// Day and Hour cannot overflow the event's time.
+const maxScriptDays = 3650
+
+// Event is something the simulated subscriber does, Day days and Hour hours
+// after the journey starts. Email is the email it responds to; 0 means the
+// most recently sent email.
+type Event struct {
+	Type  branching.Event `json:"event"` // opened, clicked or purchased
+	Day   int             `json:"day,omitempty"`
+	Hour  int             `json:"hour,omitempty"`
+	Email int             `json:"email,omitempty"`
+}
+
+func (e Event) at() time.Duration {
+	return time.Duration(e.Day)*24*time.Hour + time.Duration(e.Hour)*time.Hour
+}
+
+// StepKind classifies a Step of the timeline.
+type StepKind string
+
+const (
+	StepSend     StepKind = "send"     // an email goes out
+	StepEvent    StepKind = "event"    // the subscriber acts
+	StepIgnored  StepKind = "ignored"  // a scripted event that could not apply
+	StepBranch   StepKind = "branch"   // a jump or wait rule fired
+	StepExit     StepKind = "exit"     // an exit or start rule fired; the journey ends
+	StepComplete StepKind = "complete" // the last email went out
+)
+
+// Step is one entry of the simulated timeline.
+type Step struct {
+	AtHours int      `json:"atHours"` // hours after the journey starts
+	Day     int      `json:"day"`
+	Kind    StepKind `json:"kind"`
+	Email   int      `json:"email,omitempty"`
+	Rule    string   `json:"rule,omitempty"` // the branch that fired
+	Detail  string   `json:"detail"`
+}
+
+// Send is an email that would be sent.
+type Send struct {
+	Email   int    `json:"email"`
+	Subject string `json:"subject"`
+	AtHours int    `json:"atHours"`
+	Day     int    `json:"day"`
+}
+
+// Result is the outcome of a simulation.
+type Result struct {
+	Sends    []Send `json:"sends"`
+	Timeline []Step `json:"timeline"`
+	// Outcome is "completed", "exited" or "started <sequence>".
+	Outcome string `json:"outcome"`
+}
+
+// Run simulates j for a subscriber who behaves as events describe. Emails
+// follow their send offsets; branches are checked in order whenever the
+// subscriber acts or a no_engagement_for period runs out, and each branch
+// fires at most once. A jump sends its target email no earlier than its
+// usual gap after the previous send; a wait holds the next email back.
+func Run(j *journey.Journey, events []Event) (*Result, error) {
+	if err := j.Validate(); err != nil {
+		return nil, err
+	}
+	script, err := sortedScript(events)
+	if err != nil {
+		return nil, err
+	}
+
+	emails := append([]journey.Email(nil), j.Emails...)
+	sort.SliceStable(emails, func(a, b int) bool { return emails[a].Number < emails[b].Number })
+	s := &run{
+		journey: j,
+		emails:  emails,
+		sentAt:  make(map[int]time.Duration),
+		fired:   make(map[int]bool),
+		result:  &Result{},
+	}
+	s.nextAt = emails[0].SendOffset()
+
+	for !s.done {
+		eventAt, hasEvent := time.Duration(0), len(script) > 0
+		if hasEvent {
+			eventAt = script[0].at()
+		}
+		rule, timerAt, hasTimer := s.nextTimer()
+
+		// At equal times the subscriber acts first, then timers run out, then email goes out
+		switch {
+		case hasEvent && eventAt <= s.nextAt && (!hasTimer || eventAt <= timerAt):
+			s.handleEvent(script[0])
+			script = script[1:]
+		case hasTimer && timerAt <= s.nextAt:
+			s.now = timerAt
+			s.apply(rule, fmt.Sprintf("no engagement for %s", formatHours(time.Duration(j.Branches[rule].When.Hours)*time.Hour)))
+		default:
+			s.send()
+		}
+	}
+
+	for _, ev := range script {
+		s.step(ev.at(), StepIgnored, ev.Email, "", fmt.Sprintf("%s after the journey ended", ev.Type))
+	}
+	return s.result, nil
+}
+
+// sortedScript validates events and orders them by time, keeping the given
+// order for events at the same time.
+func sortedScript(events []Event) ([]Event, error) {
+	for i, ev := range events {
+		switch ev.Type {
+		case branching.EventOpened, branching.EventClicked, branching.EventPurchased:
+		default:
+			return nil, fmt.Errorf("%w: event %d: %q is not opened, clicked or purchased", ErrInvalidScript, i+1, ev.Type)
+		}
+		if ev.Day < 0 || ev.Hour < 0 || ev.Email < 0 {
+			return nil, fmt.Errorf("%w: event %d: day, hour and email must not be negative", ErrInvalidScript, i+1)
+		}
+		if ev.Day > maxScriptDays || ev.Hour > maxScriptDays*24 {
+			return nil, fmt.Errorf("%w: event %d: day and hour must each be within %d days", ErrInvalidScript, i+1, maxScriptDays)
+		}
+	}
+	script := append([]Event(nil), events...)
+	sort.SliceStable(script, func(a, b int) bool { return script[a].at() < script[b].at() })
+	return script, nil
+}
+
+// run is the state of one simulation.
+type run struct {
+	journey *journey.Journey
+	emails  []journey.Email // ordered by number
+	result  *Result
+
+	now        time.Duration
+	next       int           // index of the next email to send
+	nextAt     time.Duration // when it goes out
+	lastSent   int           // number of the most recently sent email; 0 before the first
+	lastSendAt time.Duration
+	lastActive time.Duration // most recent open, click or purchase
+	sentAt     map[int]time.Duration
+	fired      map[int]bool // branch index -> already fired
+	done       bool
+}
+
+// send sends the next email and schedules the one after it.
+func (s *run) send() {
+	email := s.emails[s.next]
+	s.now = s.nextAt
+	s.sentAt[email.Number] = s.now
+	s.lastSent, s.lastSendAt = email.Number, s.now
+	s.result.Sends = append(s.result.Sends, Send{
+		Email: email.Number, Subject: email.Subject, AtHours: hours(s.now), Day: days(s.now),
+	})
+	s.step(s.now, StepSend, email.Number, "", fmt.Sprintf("email %d sent: %s", email.Number, email.Subject))
+
+	s.next++
+	if s.next >= len(s.emails) {
+		s.finish(StepComplete, "", "completed", "last email sent")
+		return
+	}
+	s.nextAt = s.now + s.gap(s.next)
+}
+
+// gap is how long email index i normally waits after the email before it.
+func (s *run) gap(i int) time.Duration {
+	if i == 0 {
+		return s.emails[0].SendOffset()
+	}
+	return max(s.emails[i].SendOffset()-s.emails[i-1].SendOffset(), 0)
+}
+
+// handleEvent records a scripted event and applies the first branch it matches.
+func (s *run) handleEvent(ev Event) {
+	at := ev.at()
+	number := ev.Email
+	if number == 0 {
+		number = s.lastSent
+	}
+	sent, ok := s.sentAt[number]
+	switch {
+	case number == 0:
+		s.step(at, StepIgnored, 0, "", fmt.Sprintf("%s before any email was sent", ev.Type))
+		return
+	case !ok || sent > at:
+		s.step(at, StepIgnored, number, "", fmt.Sprintf("%s before email %d was sent", ev.Type, number))
+		return
+	}
+
+	s.now = at
+	s.lastActive = at
+	cause := fmt.Sprintf("subscriber %s email %d", ev.Type, number)
+	if ev.Type == branching.EventPurchased {
+		cause = fmt.Sprintf("subscriber purchased after email %d", number)
+	}
+	s.step(at, StepEvent, number, "", cause)
+
+	for i, r := range s.journey.Branches {
+		w := r.When
+		if s.fired[i] || w.Event != ev.Type {
+			continue
+		}
+		if w.Email != 0 && w.Email != number {
+			continue
+		}
+		if w.Hours > 0 && at-sent > time.Duration(w.Hours)*time.Hour {
+			continue
+		}
+		s.apply(i, cause)
+		return
+	}
+}
+
+// nextTimer returns the first unfired no_engagement_for branch to run out,
+// and when. The quiet period counts from the latest activity, or from the
+// send of the branch's email if that is later.
+func (s *run) nextTimer() (int, time.Duration, bool) {
+	best, bestAt, found := 0, time.Duration(0), false
+	for i, r := range s.journey.Branches {
+		if s.fired[i] || r.When.Event != branching.EventNoEngagement {
+			continue
+		}
+		from := s.lastActive
+		if r.When.Email != 0 {
+			sent, ok := s.sentAt[r.When.Email]
+			if !ok {
+				continue
+			}
+			from = max(from, sent)
+		}
+		due := from + time.Duration(r.When.Hours)*time.Hour
+		if !found || due < bestAt {
+			best, bestAt, found = i, due, true
+		}
+	}
+	return best, bestAt, found
+}
+
+// apply carries out branch i, triggered by cause.
+func (s *run) apply(i int, cause string) {
+	s.fired[i] = true
+	r := s.journey.Branches[i]
+	rule := r.String()
+
+	switch r.Then.Type {
+	case branching.ActionExit:
+		s.finish(StepExit, rule, "exited", cause+": subscriber exits the journey")
+	case branching.ActionStart:
+		s.finish(StepExit, rule, "started "+r.Then.Sequence, fmt.Sprintf("%s: subscriber leaves for the %s sequence", cause, r.Then.Sequence))
+	case branching.ActionJump:
+		for k, email := range s.emails {
+			if email.Number == r.Then.Email {
+				s.next = k
+				s.nextAt = max(s.now, s.lastSendAt+s.gap(k))
+			}
+		}
+		s.step(s.now, StepBranch, r.Then.Email, rule, fmt.Sprintf("%s: jump to email %d, sending at hour %d", cause, r.Then.Email, hours(s.nextAt)))
+	case branching.ActionWait:
+		s.nextAt = max(s.nextAt, s.now) + time.Duration(r.Then.Hours)*time.Hour
+		s.step(s.now, StepBranch, s.emails[s.next].Number, rule, fmt.Sprintf("%s: email %d held until hour %d", cause, s.emails[s.next].Number, hours(s.nextAt)))
+	}
+}
+
+// finish ends the simulation with a final step.
+func (s *run) finish(kind StepKind, rule, outcome, detail string) {
+	s.step(s.now, kind, 0, rule, detail)
+	s.result.Outcome = outcome
+	s.done = true
+}
+
+func (s *run) step(at time.Duration, kind StepKind, email int, rule, detail string) {
+	s.result.Timeline = append(s.result.Timeline, Step{
+		AtHours: hours(at), Day: days(at), Kind: kind, Email: email, Rule: rule, Detail: detail,
+	})
+}
+
+func hours(d time.Duration) int { return int(d / time.Hour) }
+
+func days(d time.Duration) int { return int(d / (24 * time.Hour)) }
+
+// formatHours renders a duration as hours, or whole days when it divides evenly.
+func formatHours(d time.Duration) string {
+	if h := hours(d); h%24 != 0 {
+		return fmt.Sprintf("%dh", h)
+	}
+	return fmt.Sprintf("%dd", days(d))
+}
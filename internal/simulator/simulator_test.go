@@ -0,0 +1,184 @@
+package simulator
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+
+	"JourneyBuilder/internal/branching"
+	"JourneyBuilder/internal/journey"
+)
+
+// testJourney returns a journey with one email per send hour and the given rules.
+func testJourney(t *testing.T, rules string, sendHours ...int) *journey.Journey {
+	t.Helper()
+	branches, err := branching.Parse(rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	j := &journey.Journey{Branches: branches}
+	for i, h := range sendHours {
+		j.Emails = append(j.Emails, journey.Email{Number: i + 1, Subject: "Email", DayDelay: h / 24, SendAfterHours: h})
+	}
+	return j
+}
+
+// sent returns "email@hour" for every send.
+func sent(res *Result) []string {
+	var out []string
+	for _, s := range res.Sends {
+		out = append(out, fmt.Sprintf("%d@%d", s.Email, s.AtHours))
+	}
+	return out
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name     string
+		rules    string
+		events   []Event
+		sends    []string
+		outcome  string
+		lastKind StepKind
+		lastAt   int
+	}{
+		{
+			name:    "completed",
+			sends:   []string{"1@0", "2@48", "3@120", "4@168"},
+			outcome: "completed", lastKind: StepComplete, lastAt: 168,
+		},
+		{
+			name:    "exit on purchase",
+			rules:   "IF purchased THEN exit",
+			events:  []Event{{Type: branching.EventOpened, Day: 1}, {Type: branching.EventPurchased, Day: 3}},
+			sends:   []string{"1@0", "2@48"},
+			outcome: "exited", lastKind: StepExit, lastAt: 72,
+		},
+		{
+			name:    "start another sequence",
+			rules:   "IF clicked email 2 THEN start win_back",
+			events:  []Event{{Type: branching.EventClicked, Email: 1, Hour: 5}, {Type: branching.EventClicked, Day: 2, Hour: 2}},
+			sends:   []string{"1@0", "2@48"},
+			outcome: "started win_back", lastKind: StepExit, lastAt: 50,
+		},
+		{
+			// The target keeps its usual gap after the last send
+			name:    "jump waits for the target's gap",
+			rules:   "IF clicked email 1 THEN jump to email 3",
+			events:  []Event{{Type: branching.EventClicked, Hour: 24}},
+			sends:   []string{"1@0", "3@72", "4@120"},
+			outcome: "completed", lastKind: StepComplete, lastAt: 120,
+		},
+		{
+			// The gap has already passed, so the target goes out at once
+			name:    "jump sends at once",
+			rules:   "IF clicked THEN jump to email 4",
+			events:  []Event{{Type: branching.EventClicked, Hour: 100}},
+			sends:   []string{"1@0", "2@48", "4@100"},
+			outcome: "completed", lastKind: StepComplete, lastAt: 100,
+		},
+		{
+			name:    "wait holds the next email",
+			rules:   "IF opened email 1 THEN wait 2 days",
+			events:  []Event{{Type: branching.EventOpened, Hour: 10}, {Type: branching.EventOpened, Email: 1, Day: 2}},
+			sends:   []string{"1@0", "2@96", "3@168", "4@216"},
+			outcome: "completed", lastKind: StepComplete, lastAt: 216,
+		},
+		{
+			name:    "no engagement timer",
+			rules:   "IF no_engagement_for 3 days THEN exit",
+			sends:   []string{"1@0", "2@48"},
+			outcome: "exited", lastKind: StepExit, lastAt: 72,
+		},
+		{
+			// Activity restarts the quiet period
+			name:    "no engagement timer after activity",
+			rules:   "IF no_engagement_for 3 days THEN exit",
+			events:  []Event{{Type: branching.EventOpened, Hour: 50}},
+			sends:   []string{"1@0", "2@48", "3@120"},
+			outcome: "exited", lastKind: StepExit, lastAt: 122,
+		},
+		{
+			name:    "no engagement timer for an email",
+			rules:   "IF no_engagement_for 1 day email 3 THEN jump to email 4",
+			sends:   []string{"1@0", "2@48", "3@120", "4@168"},
+			outcome: "completed", lastKind: StepComplete, lastAt: 168,
+		},
+		{
+			name:    "within window missed",
+			rules:   "IF clicked email 1 within 12 hours THEN exit",
+			events:  []Event{{Type: branching.EventClicked, Email: 1, Hour: 20}},
+			sends:   []string{"1@0", "2@48", "3@120", "4@168"},
+			outcome: "completed", lastKind: StepComplete, lastAt: 168,
+		},
+	}
+	for _, tt := range tests {
+		res, err := Run(testJourney(t, tt.rules, 0, 48, 120, 168), tt.events)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if got := sent(res); !reflect.DeepEqual(got, tt.sends) {
+			t.Errorf("%s: sends = %v, want %v", tt.name, got, tt.sends)
+		}
+		if res.Outcome != tt.outcome {
+			t.Errorf("%s: outcome = %q, want %q", tt.name, res.Outcome, tt.outcome)
+		}
+		last := res.Timeline[len(res.Timeline)-1]
+		if last.Kind != tt.lastKind || last.AtHours != tt.lastAt {
+			t.Errorf("%s: last step = %s at hour %d, want %s at hour %d", tt.name, last.Kind, last.AtHours, tt.lastKind, tt.lastAt)
+		}
+	}
+}
+
+func TestRunIgnoresEventsBeforeSends(t *testing.T) {
+	j := testJourney(t, "IF purchased THEN exit; IF clicked email 2 THEN exit", 12, 48)
+	res, err := Run(j, []Event{
+		{Type: branching.EventPurchased, Hour: 2},
+		{Type: branching.EventClicked, Email: 2, Hour: 20},
+		{Type: branching.EventOpened, Day: 5},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := sent(res), []string{"1@12", "2@48"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("sends = %v, want %v", got, want)
+	}
+	if res.Outcome != "completed" {
+		t.Errorf("outcome = %q, want completed", res.Outcome)
+	}
+
+	var ignored []string
+	for _, step := range res.Timeline {
+		if step.Kind == StepIgnored {
+			ignored = append(ignored, step.Detail)
+		}
+	}
+	want := []string{
+		"purchased before any email was sent",
+		"clicked before email 2 was sent",
+		"opened after the journey ended",
+	}
+	if !reflect.DeepEqual(ignored, want) {
+		t.Errorf("ignored = %q, want %q", ignored, want)
+	}
+}
+
+func TestRunInvalidScript(t *testing.T) {
+	j := testJourney(t, "", 0, 48)
+	for _, ev := range []Event{
+		{Type: "viewed"},
+		{Type: branching.EventNoEngagement, Day: 1},
+		{Type: branching.EventOpened, Day: -1},
+		{Type: branching.EventOpened, Email: -1},
+		{Type: branching.EventOpened, Day: maxScriptDays + 1},
+		{Type: branching.EventOpened, Day: math.MaxInt},
+		{Type: branching.EventOpened, Hour: math.MaxInt},
+	} {
+		if _, err := Run(j, []Event{ev}); !errors.Is(err, ErrInvalidScript) {
+			t.Errorf("Run with %+v: err = %v, want ErrInvalidScript", ev, err)
+		}
+	}
+}
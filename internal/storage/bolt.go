@@ -0,0 +1,197 @@
+package storage
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"JourneyBuilder/internal/journey"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	// journeysBucket holds one nested bucket per journey ID, mapping
+	// big-endian version numbers to encoded journeys.
+	journeysBucket = []byte("journeys")
+	// workspacesBucket holds one nested bucket per workspace whose keys are
+	// the IDs of its journeys.
+	workspacesBucket = []byte("workspaces")
+)
+
+// BoltStore keeps journeys in a single BoltDB file.
+type BoltStore struct {
+	db  *bolt.DB
+	now func() time.Time
+}
+
+var _ JourneyStore = (*BoltStore)(nil)
+
+// OpenBolt opens or creates the database at path. It fails after a second
+// if another process holds the file open.
+func OpenBolt(path string) (*BoltStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create journey store: %w", err)
+	}
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journey store %s: %w", path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{journeysBucket, workspacesBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize journey store %s: %w", path, err)
+	}
+	return &BoltStore{db: db, now: time.Now}, nil
+}
+
+func (s *BoltStore) Save(j *journey.Journey) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		journeys := tx.Bucket(journeysBucket)
+
+		var latest *journey.Journey
+		var versions *bolt.Bucket
+		if j.ID == "" {
+			j.ID = NewID()
+			for journeys.Bucket([]byte(j.ID)) != nil {
+				j.ID = NewID()
+			}
+		} else {
+			if versions = journeys.Bucket([]byte(j.ID)); versions == nil {
+				return ErrNotFound
+			}
+			_, data := versions.Cursor().Last()
+			var err error
+			if latest, err = decode(data); err != nil {
+				return err
+			}
+		}
+
+		if err := prepare(j, latest, s.now()); err != nil {
+			return err
+		}
+		data, err := encode(j)
+		if err != nil {
+			return err
+		}
+
+		if versions == nil {
+			if versions, err = journeys.CreateBucket([]byte(j.ID)); err != nil {
+				return fmt.Errorf("failed to save journey: %w", err)
+			}
+			workspace, err := tx.Bucket(workspacesBucket).CreateBucketIfNotExists([]byte(j.Workspace))
+			if err != nil {
+				return fmt.Errorf("failed to save journey: %w", err)
+			}
+			if err := workspace.Put([]byte(j.ID), nil); err != nil {
+				return fmt.Errorf("failed to save journey: %w", err)
+			}
+		}
+		if err := versions.Put(versionKey(j.Version), data); err != nil {
+			return fmt.Errorf("failed to save journey: %w", err)
+		}
+		return nil
+	})
+}
+
+func (s *BoltStore) Load(id string, version int) (*journey.Journey, error) {
+	if !idPattern.MatchString(id) || version < 0 {
+		return nil, ErrNotFound
+	}
+	var j *journey.Journey
+	err := s.db.View(func(tx *bolt.Tx) error {
+		versions := tx.Bucket(journeysBucket).Bucket([]byte(id))
+		if versions == nil {
+			return ErrNotFound
+		}
+		var data []byte
+		if version == 0 {
+			_, data = versions.Cursor().Last()
+		} else {
+			data = versions.Get(versionKey(version))
+		}
+		if data == nil {
+			return ErrNotFound
+		}
+		var err error
+		j, err = decode(data)
+		return err
+	})
+	return j, err
+}
+
+func (s *BoltStore) ListByWorkspace(workspace string) ([]Summary, error) {
+	workspace, err := workspaceOrDefault(workspace)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := []Summary{}
+	err = s.db.View(func(tx *bolt.Tx) error {
+		ids := tx.Bucket(workspacesBucket).Bucket([]byte(workspace))
+		if ids == nil {
+			return nil
+		}
+		journeys := tx.Bucket(journeysBucket)
+		return ids.ForEach(func(id, _ []byte) error {
+			versions := journeys.Bucket(id)
+			if versions == nil {
+				return nil
+			}
+			_, data := versions.Cursor().Last()
+			j, err := decode(data)
+			if err != nil {
+				return err
+			}
+			summaries = append(summaries, Summarize(j))
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	sortRecent(summaries)
+	return summaries, nil
+}
+
+func (s *BoltStore) ListVersions(id string) ([]Summary, error) {
+	if !idPattern.MatchString(id) {
+		return nil, ErrNotFound
+	}
+	var summaries []Summary
+	err := s.db.View(func(tx *bolt.Tx) error {
+		versions := tx.Bucket(journeysBucket).Bucket([]byte(id))
+		if versions == nil {
+			return ErrNotFound
+		}
+		return versions.ForEach(func(_, data []byte) error {
+			j, err := decode(data)
+			if err != nil {
+				return err
+			}
+			summaries = append(summaries, Summarize(j))
+			return nil
+		})
+	})
+	return summaries, err
+}
+
+func (s *BoltStore) Close() error {
+	return s.db.Close()
+}
+
+// versionKey encodes a version so that keys sort in version order.
+func versionKey(version int) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, uint64(version))
+	return key
+}
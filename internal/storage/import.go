@@ -0,0 +1,149 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"JourneyBuilder/internal/journey"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// ImportFiles copies journeys kept by the old file store, one JSON file per
+// version under <dir>/<id>/v<version>.json, into the database. IDs and
+// version numbers are kept; journeys get DefaultWorkspace and, where the
+// file has no save time, their confirmation time or the file's modification
+// time. Journeys already in the database are skipped, so importing again is
+// harmless. It returns the number of journeys imported; a missing dir
+// imports none.
+func (s *BoltStore) ImportFiles(dir string) (int, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+
+	imported := 0
+	for _, entry := range entries {
+		id := entry.Name()
+		if !entry.IsDir() || !idPattern.MatchString(id) {
+			continue
+		}
+		versions, err := readVersionFiles(filepath.Join(dir, id), id)
+		if err != nil {
+			return imported, err
+		}
+		if len(versions) == 0 {
+			continue
+		}
+		ok, err := s.importJourney(id, versions)
+		if err != nil {
+			return imported, err
+		}
+		if ok {
+			imported++
+		}
+	}
+	return imported, nil
+}
+
+// readVersionFiles decodes the version files of one journey, in version order.
+func readVersionFiles(dir, id string) ([]*journey.Journey, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+
+	var versions []*journey.Journey
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, "v") || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		version, err := strconv.Atoi(strings.TrimSuffix(name[1:], ".json"))
+		if err != nil || version <= 0 {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		var j journey.Journey
+		if err := json.Unmarshal(data, &j); err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+		}
+
+		j.ID, j.Version = id, version
+		if j.Workspace == "" {
+			j.Workspace = DefaultWorkspace
+		}
+		if j.SavedAt == nil {
+			saved := j.ConfirmedAt
+			if saved == nil {
+				info, err := entry.Info()
+				if err != nil {
+					return nil, fmt.Errorf("failed to read %s: %w", path, err)
+				}
+				modified := info.ModTime().UTC()
+				saved = &modified
+			}
+			j.SavedAt = saved
+		}
+		versions = append(versions, &j)
+	}
+	sort.Slice(versions, func(a, b int) bool { return versions[a].Version < versions[b].Version })
+	return versions, nil
+}
+
+// importJourney stores every version of a journey unless its ID is taken.
+func (s *BoltStore) importJourney(id string, versions []*journey.Journey) (bool, error) {
+	imported := false
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		journeys := tx.Bucket(journeysBucket)
+		if journeys.Bucket([]byte(id)) != nil {
+			return nil
+		}
+		bucket, err := journeys.CreateBucket([]byte(id))
+		if err != nil {
+			return err
+		}
+		for _, j := range versions {
+			if _, err := workspaceOrDefault(j.Workspace); err != nil {
+				return fmt.Errorf("journey %s v%d: %w", id, j.Version, err)
+			}
+			if j.Workspace != versions[0].Workspace {
+				return fmt.Errorf("journey %s v%d: %w", id, j.Version, ErrWorkspaceMismatch)
+			}
+			data, err := encode(j)
+			if err != nil {
+				return err
+			}
+			if err := bucket.Put(versionKey(j.Version), data); err != nil {
+				return err
+			}
+		}
+		workspace, err := tx.Bucket(workspacesBucket).CreateBucketIfNotExists([]byte(versions[0].Workspace))
+		if err != nil {
+			return err
+		}
+		if err := workspace.Put([]byte(id), nil); err != nil {
+			return err
+		}
+		imported = true
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to import journey %s: %w", id, err)
+	}
+	return imported, nil
+}
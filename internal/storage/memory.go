@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"sync"
+	"time"
+
+	"JourneyBuilder/internal/journey"
+)
+
+// MemoryStore keeps journeys in memory, for tests and for running without
+// a database. Versions are kept encoded, so callers never share them.
+type MemoryStore struct {
+	mu       sync.RWMutex
+	journeys map[string][][]byte // id -> versions, oldest first
+	now      func() time.Time
+}
+
+var _ JourneyStore = (*MemoryStore)(nil)
+
+// NewMemoryStore returns an empty store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{journeys: make(map[string][][]byte), now: time.Now}
+}
+
+func (s *MemoryStore) Save(j *journey.Journey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var latest *journey.Journey
+	if j.ID == "" {
+		j.ID = NewID()
+		for s.journeys[j.ID] != nil {
+			j.ID = NewID()
+		}
+	} else {
+		versions := s.journeys[j.ID]
+		if versions == nil {
+			return ErrNotFound
+		}
+		var err error
+		if latest, err = decode(versions[len(versions)-1]); err != nil {
+			return err
+		}
+	}
+
+	if err := prepare(j, latest, s.now()); err != nil {
+		return err
+	}
+	data, err := encode(j)
+	if err != nil {
+		return err
+	}
+	s.journeys[j.ID] = append(s.journeys[j.ID], data)
+	return nil
+}
+
+func (s *MemoryStore) Load(id string, version int) (*journey.Journey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	versions := s.journeys[id]
+	if version == 0 {
+		version = len(versions)
+	}
+	if version < 1 || version > len(versions) {
+		return nil, ErrNotFound
+	}
+	return decode(versions[version-1])
+}
+
+func (s *MemoryStore) ListByWorkspace(workspace string) ([]Summary, error) {
+	workspace, err := workspaceOrDefault(workspace)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	summaries := []Summary{}
+	for _, versions := range s.journeys {
+		j, err := decode(versions[len(versions)-1])
+		if err != nil {
+			return nil, err
+		}
+		if j.Workspace == workspace {
+			summaries = append(summaries, Summarize(j))
+		}
+	}
+	sortRecent(summaries)
+	return summaries, nil
+}
+
+func (s *MemoryStore) ListVersions(id string) ([]Summary, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	versions := s.journeys[id]
+	if versions == nil {
+		return nil, ErrNotFound
+	}
+	summaries := make([]Summary, len(versions))
+	for i, data := range versions {
+		j, err := decode(data)
+		if err != nil {
+			return nil, err
+		}
+		summaries[i] = Summarize(j)
+	}
+	return summaries, nil
+}
+
+func (s *MemoryStore) Close() error { return nil }
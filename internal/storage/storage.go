@@ -0,0 +1,157 @@
+// Package storage persists journeys, both drafts and confirmed ones. Every
+// save creates a new, immutable version, and all versions of a journey
+// belong to the same workspace.
+package storage
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"JourneyBuilder/internal/journey"
+)
+
+// DefaultWorkspace holds journeys saved without a workspace.
+const DefaultWorkspace = "default"
+
+var (
+	// ErrNotFound is returned when a journey or version does not exist.
+	ErrNotFound = errors.New("journey not found")
+	// ErrInvalidWorkspace is returned for workspace names other than lowercase letters, digits, "-" and "_".
+	ErrInvalidWorkspace = errors.New("invalid workspace")
+	// ErrWorkspaceMismatch is returned when a new version names a different workspace than the journey's.
+	ErrWorkspaceMismatch = errors.New("journey belongs to another workspace")
+)
+
+// JourneyStore persists journeys as immutable versions.
+type JourneyStore interface {
+	// Save stores j as its next version and sets j.ID, j.Version,
+	// j.Workspace and j.SavedAt. A journey without an ID is stored as a new
+	// journey, in DefaultWorkspace unless it names one; saving an unknown ID
+	// returns ErrNotFound.
+	Save(j *journey.Journey) error
+	// Load returns a stored version of a journey; version 0 means the latest.
+	Load(id string, version int) (*journey.Journey, error)
+	// ListByWorkspace summarizes the latest version of every journey in a
+	// workspace, most recently saved first. An unknown workspace has none.
+	ListByWorkspace(workspace string) ([]Summary, error)
+	// ListVersions summarizes every version of a journey in ascending order.
+	ListVersions(id string) ([]Summary, error)
+	// Close releases the store.
+	Close() error
+}
+
+// Summary describes one stored version without its email copy.
+type Summary struct {
+	ID          string     `json:"id"`
+	Version     int        `json:"version"`
+	Workspace   string     `json:"workspace"`
+	Outcome     string     `json:"outcome,omitempty"`
+	Vertical    string     `json:"vertical,omitempty"`
+	Emails      int        `json:"emails"`
+	SavedAt     time.Time  `json:"savedAt"`
+	ConfirmedAt *time.Time `json:"confirmedAt,omitempty"` // nil for drafts
+}
+
+// Summarize describes a stored journey.
+func Summarize(j *journey.Journey) Summary {
+	s := Summary{
+		ID:          j.ID,
+		Version:     j.Version,
+		Workspace:   j.Workspace,
+		Outcome:     j.Outcome,
+		Vertical:    j.Vertical,
+		Emails:      len(j.Emails),
+		ConfirmedAt: j.ConfirmedAt,
+	}
+	if j.SavedAt != nil {
+		s.SavedAt = *j.SavedAt
+	}
+	return s
+}
+
+var (
+	// idPattern restricts IDs to what NewID produces.
+	idPattern        = regexp.MustCompile(`^[a-z0-9]{1,64}$`)
+	workspacePattern = regexp.MustCompile(`^[a-z0-9_-]{1,64}$`)
+)
+
+// NewID returns a random journey ID.
+func NewID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(b)
+}
+
+// workspaceOrDefault maps "" to DefaultWorkspace and checks the name.
+func workspaceOrDefault(workspace string) (string, error) {
+	if workspace == "" {
+		return DefaultWorkspace, nil
+	}
+	if !workspacePattern.MatchString(workspace) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidWorkspace, workspace)
+	}
+	return workspace, nil
+}
+
+// prepare sets the fields Save is responsible for. latest is the newest
+// stored version of j, or nil when j is new; the caller assigns new IDs.
+func prepare(j *journey.Journey, latest *journey.Journey, now time.Time) error {
+	workspace := j.Workspace
+	if latest != nil {
+		switch workspace {
+		case "":
+			workspace = latest.Workspace
+		case latest.Workspace:
+		default:
+			return fmt.Errorf("%w: %s is in %q", ErrWorkspaceMismatch, j.ID, latest.Workspace)
+		}
+	}
+	workspace, err := workspaceOrDefault(workspace)
+	if err != nil {
+		return err
+	}
+
+	j.Workspace = workspace
+	j.Version = 1
+	if latest != nil {
+		j.Version = latest.Version + 1
+	}
+	saved := now.UTC()
+	j.SavedAt = &saved
+	return nil
+}
+
+func encode(j *journey.Journey) ([]byte, error) {
+	data, err := json.Marshal(j)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode journey: %w", err)
+	}
+	return data, nil
+}
+
+func decode(data []byte) (*journey.Journey, error) {
+	var j journey.Journey
+	if err := json.Unmarshal(data, &j); err != nil {
+		return nil, fmt.Errorf("failed to decode journey: %w", err)
+	}
+	return &j, nil
+}
+
+// sortRecent orders summaries most recently saved first, then by ID.
+func sortRecent(summaries []Summary) {
+	sort.Slice(summaries, func(a, b int) bool {
+		if !summaries[a].SavedAt.Equal(summaries[b].SavedAt) {
+			return summaries[a].SavedAt.After(summaries[b].SavedAt)
+		}
+		return summaries[a].ID < summaries[b].ID
+	})
+}
@@ -0,0 +1,206 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"JourneyBuilder/internal/journey"
+)
+
+// testStores returns each store kind, empty, with a clock that advances a
+// minute per save.
+func testStores(t *testing.T) map[string]JourneyStore {
+	t.Helper()
+	clock := func() func() time.Time {
+		now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+		return func() time.Time {
+			now = now.Add(time.Minute)
+			return now
+		}
+	}
+
+	memory := NewMemoryStore()
+	memory.now = clock()
+	bolt, err := OpenBolt(filepath.Join(t.TempDir(), "journeys.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { bolt.Close() })
+	bolt.now = clock()
+	return map[string]JourneyStore{"memory": memory, "bolt": bolt}
+}
+
+func newJourney(outcome string) *journey.Journey {
+	return &journey.Journey{Outcome: outcome, Emails: []journey.Email{{Number: 1, Subject: "Hello"}}}
+}
+
+func TestSaveVersions(t *testing.T) {
+	for name, store := range testStores(t) {
+		j := newJourney("Cart Recovery")
+		if err := store.Save(j); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if j.ID == "" || j.Version != 1 || j.Workspace != DefaultWorkspace || j.SavedAt == nil {
+			t.Fatalf("%s: first save set ID %q, version %d, workspace %q", name, j.ID, j.Version, j.Workspace)
+		}
+
+		// Later saves add versions; the journey keeps its workspace
+		j.Outcome = "Cart Recovery v2"
+		j.Workspace = ""
+		if err := store.Save(j); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if j.Version != 2 || j.Workspace != DefaultWorkspace {
+			t.Errorf("%s: second save: version %d, workspace %q", name, j.Version, j.Workspace)
+		}
+
+		latest, err := store.Load(j.ID, 0)
+		if err != nil || latest.Version != 2 || latest.Outcome != "Cart Recovery v2" {
+			t.Errorf("%s: Load latest = %+v, %v", name, latest, err)
+		}
+		first, err := store.Load(j.ID, 1)
+		if err != nil || first.Version != 1 || first.Outcome != "Cart Recovery" {
+			t.Errorf("%s: Load v1 = %+v, %v", name, first, err)
+		}
+
+		versions, err := store.ListVersions(j.ID)
+		if err != nil || len(versions) != 2 || versions[0].Version != 1 || versions[1].Version != 2 {
+			t.Errorf("%s: ListVersions = %+v, %v", name, versions, err)
+		}
+
+		j.Workspace = "acme"
+		if err := store.Save(j); !errors.Is(err, ErrWorkspaceMismatch) {
+			t.Errorf("%s: save into another workspace: err = %v, want ErrWorkspaceMismatch", name, err)
+		}
+	}
+}
+
+func TestNotFound(t *testing.T) {
+	for name, store := range testStores(t) {
+		j := newJourney("Habit Formation")
+		if err := store.Save(j); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		unknown := &journey.Journey{ID: "0123456789abcdef", Emails: j.Emails}
+		if err := store.Save(unknown); !errors.Is(err, ErrNotFound) {
+			t.Errorf("%s: Save unknown ID: err = %v, want ErrNotFound", name, err)
+		}
+		for _, tt := range []struct {
+			id      string
+			version int
+		}{
+			{"0123456789abcdef", 0},
+			{j.ID, 2},
+			{j.ID, -1},
+			{"../journeys", 0},
+		} {
+			if _, err := store.Load(tt.id, tt.version); !errors.Is(err, ErrNotFound) {
+				t.Errorf("%s: Load(%q, %d): err = %v, want ErrNotFound", name, tt.id, tt.version, err)
+			}
+		}
+		if _, err := store.ListVersions("0123456789abcdef"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("%s: ListVersions unknown ID: err = %v, want ErrNotFound", name, err)
+		}
+	}
+}
+
+func TestListByWorkspace(t *testing.T) {
+	for name, store := range testStores(t) {
+		var ids []string
+		for _, ws := range []string{"acme", "acme", "other", "acme"} {
+			j := newJourney("Journey")
+			j.Workspace = ws
+			if err := store.Save(j); err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			ids = append(ids, j.ID)
+		}
+		// A new version of the oldest journey makes it the most recent
+		oldest, err := store.Load(ids[0], 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := store.Save(oldest); err != nil {
+			t.Fatal(err)
+		}
+
+		summaries, err := store.ListByWorkspace("acme")
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		want := []string{ids[0], ids[3], ids[1]}
+		if len(summaries) != len(want) {
+			t.Fatalf("%s: listed %d journeys, want %d", name, len(summaries), len(want))
+		}
+		for i, s := range summaries {
+			if s.ID != want[i] {
+				t.Errorf("%s: summary %d is %s, want %s", name, i, s.ID, want[i])
+			}
+		}
+		if summaries[0].Version != 2 {
+			t.Errorf("%s: summary lists version %d, want the latest", name, summaries[0].Version)
+		}
+
+		if none, err := store.ListByWorkspace("empty"); err != nil || len(none) != 0 {
+			t.Errorf("%s: unknown workspace: %v, %v", name, none, err)
+		}
+		if _, err := store.ListByWorkspace("Bad Name"); !errors.Is(err, ErrInvalidWorkspace) {
+			t.Errorf("%s: invalid workspace: err = %v, want ErrInvalidWorkspace", name, err)
+		}
+	}
+}
+
+func TestImportFiles(t *testing.T) {
+	dir := t.TempDir()
+	confirmed := time.Date(2026, 1, 5, 12, 0, 0, 0, time.UTC)
+	files := map[string]string{
+		"abc123/v1.json":   `{"id":"abc123","version":1,"confirmedAt":"2026-01-05T12:00:00Z","outcome":"Old","emails":[{"number":1,"subject":"Hi","dayDelay":0}]}`,
+		"abc123/v2.json":   `{"id":"abc123","version":2,"confirmedAt":"2026-01-05T12:00:00Z","outcome":"Newer","emails":[{"number":1,"subject":"Hi","dayDelay":0}]}`,
+		"abc123/notes.txt": "not a version",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	store, err := OpenBolt(filepath.Join(t.TempDir(), "journeys.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	for _, want := range []int{1, 0} {
+		n, err := store.ImportFiles(dir)
+		if err != nil || n != want {
+			t.Fatalf("ImportFiles = %d, %v; want %d", n, err, want)
+		}
+	}
+	j, err := store.Load("abc123", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if j.Version != 2 || j.Outcome != "Newer" || j.Workspace != DefaultWorkspace || j.SavedAt == nil || !j.SavedAt.Equal(confirmed) {
+		t.Errorf("imported journey = %+v", j)
+	}
+	summaries, err := store.ListByWorkspace("")
+	if err != nil || len(summaries) != 1 {
+		t.Errorf("ListByWorkspace = %+v, %v", summaries, err)
+	}
+
+	// Saving continues after the imported versions
+	if err := store.Save(j); err != nil || j.Version != 3 {
+		t.Errorf("Save after import: version %d, %v", j.Version, err)
+	}
+	if n, err := store.ImportFiles(filepath.Join(dir, "missing")); n != 0 || err != nil {
+		t.Errorf("ImportFiles of a missing dir = %d, %v", n, err)
+	}
+}
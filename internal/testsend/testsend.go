@@ -0,0 +1,174 @@
+// Package testsend delivers a journey's emails to an SMTP server, in
+// order, for an end-to-end preview without an ESP. It is meant for local
+// sinks such as MailHog, not for sending to subscribers.
+package testsend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"os"
+	"strings"
+	"time"
+
+	"JourneyBuilder/internal/eml"
+	"JourneyBuilder/internal/journey"
+)
+
+// DefaultSMTPAddr is MailHog's SMTP port on the local machine.
+const DefaultSMTPAddr = "localhost:1025"
+
+// MaxWait bounds how long a test send may take once delays are compressed.
+// A test send runs within its HTTP request, so this stays well below the
+// timeouts of clients and proxies.
+const MaxWait = 30 * time.Second
+
+var (
+	// ErrTooSlow is returned when a journey's delays, after time compression,
+	// would keep the test send running longer than MaxWait.
+	ErrTooSlow = errors.New("test send would take too long")
+	// ErrRecipientNotAllowed is returned for a recipient that is neither the
+	// configured one nor in Config.AllowedRecipients.
+	ErrRecipientNotAllowed = errors.New("recipient is not allowed for test sends")
+)
+
+// Config says where and how to send.
+type Config struct {
+	SMTPAddr string // host:port; DefaultSMTPAddr when empty
+	// Username and Password enable PLAIN auth, which net/smtp only allows
+	// over TLS or to localhost. Sinks need neither.
+	Username, Password string
+	Message            eml.Config
+	// AllowedRecipients lists the addresses, or "@domain" for a whole
+	// domain, that WithRecipient accepts besides Message.To.
+	AllowedRecipients []string
+}
+
+// ConfigFromEnv reads SMTP_ADDR, SMTP_USERNAME, SMTP_PASSWORD and the
+// comma-separated TEST_SEND_RECIPIENTS, plus the message settings (see
+// eml.ConfigFromEnv).
+func ConfigFromEnv() Config {
+	var allowed []string
+	for _, entry := range strings.Split(os.Getenv("TEST_SEND_RECIPIENTS"), ",") {
+		if entry = strings.ToLower(strings.TrimSpace(entry)); entry != "" {
+			allowed = append(allowed, entry)
+		}
+	}
+	return Config{
+		SMTPAddr:          os.Getenv("SMTP_ADDR"),
+		Username:          os.Getenv("SMTP_USERNAME"),
+		Password:          os.Getenv("SMTP_PASSWORD"),
+		Message:           eml.ConfigFromEnv(),
+		AllowedRecipients: allowed,
+	}
+}
+
+// WithRecipient returns c sending to to instead of Message.To. Only the
+// configured recipient and AllowedRecipients are accepted, so callers
+// cannot turn the SMTP server into a relay to arbitrary addresses.
+func (c Config) WithRecipient(to string) (Config, error) {
+	addr, err := mail.ParseAddress(to)
+	if err != nil {
+		return c, fmt.Errorf("invalid recipient %q: %w", to, err)
+	}
+	address := strings.ToLower(addr.Address)
+
+	allowed := false
+	if _, configured, err := c.Message.Addresses(); err == nil && strings.EqualFold(configured.Address, address) {
+		allowed = true
+	}
+	for _, entry := range c.AllowedRecipients {
+		if entry == address || strings.HasPrefix(entry, "@") && strings.HasSuffix(address, entry) {
+			allowed = true
+		}
+	}
+	if !allowed {
+		return c, fmt.Errorf("%w: %s (add it to TEST_SEND_RECIPIENTS)", ErrRecipientNotAllowed, addr.Address)
+	}
+	c.Message.To = to
+	return c, nil
+}
+
+// Result reports the delivery of one email.
+type Result struct {
+	Number  int        `json:"number"`
+	Subject string     `json:"subject"`
+	SentAt  *time.Time `json:"sentAt,omitempty"`
+	OK      bool       `json:"ok"`
+	Error   string     `json:"error,omitempty"`
+}
+
+// Send delivers every email of j in order. With a compression factor
+// above 1, each email waits for its send offset divided by the factor, so
+// 86400 plays a day out in a second; otherwise the emails go out back to
+// back. Messages are dated as they would be in a live send. A failed
+// delivery is reported in its Result and the rest are still sent. Send
+// fails up front for invalid journeys or settings; if ctx ends midway it
+// returns the results so far, with the unsent emails marked as failed.
+func Send(ctx context.Context, j *journey.Journey, cfg Config, compression float64) ([]Result, error) {
+	start := time.Now()
+	messages, err := eml.Build(j, cfg.Message, start)
+	if err != nil {
+		return nil, err
+	}
+	from, to, err := cfg.Message.Addresses()
+	if err != nil {
+		return nil, err
+	}
+	waits := make([]time.Duration, len(messages))
+	for i, m := range messages {
+		if compression > 1 {
+			waits[i] = time.Duration(float64(m.Date.Sub(start)) / compression)
+		}
+	}
+	if len(waits) > 0 && waits[len(waits)-1] > MaxWait {
+		return nil, fmt.Errorf("%w: %s with time compression %g, the limit is %s",
+			ErrTooSlow, waits[len(waits)-1].Round(time.Second), compression, MaxWait)
+	}
+
+	addr := cfg.SMTPAddr
+	if addr == "" {
+		addr = DefaultSMTPAddr
+	}
+	var auth smtp.Auth
+	if cfg.Username != "" {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SMTP address %q: %w", addr, err)
+		}
+		auth = smtp.PlainAuth("", cfg.Username, cfg.Password, host)
+	}
+
+	results := make([]Result, len(messages))
+	for i, m := range messages {
+		results[i] = Result{Number: m.Email.Number, Subject: m.Email.Subject}
+		if err := sleepUntil(ctx, start.Add(waits[i])); err != nil {
+			for k := i; k < len(messages); k++ {
+				results[k] = Result{Number: messages[k].Email.Number, Subject: messages[k].Email.Subject, Error: "not sent: " + err.Error()}
+			}
+			return results, err
+		}
+		if err := smtp.SendMail(addr, auth, from.Address, []string{to.Address}, m.Data); err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		sentAt := time.Now()
+		results[i].SentAt, results[i].OK = &sentAt, true
+	}
+	return results, nil
+}
+
+// sleepUntil waits for t, or for ctx to end.
+func sleepUntil(ctx context.Context, t time.Time) error {
+	timer := time.NewTimer(time.Until(t))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
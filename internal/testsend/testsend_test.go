@@ -0,0 +1,267 @@
+package testsend
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"net/mail"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"JourneyBuilder/internal/eml"
+	"JourneyBuilder/internal/journey"
+	"JourneyBuilder/internal/render"
+)
+
+// received is one message accepted by the fake server.
+type received struct {
+	at   time.Time
+	from string
+	to   []string
+	msg  *mail.Message
+}
+
+// smtpSink is a minimal SMTP server on a loopback port that accepts every
+// message.
+type smtpSink struct {
+	ln   net.Listener
+	mu   sync.Mutex
+	msgs []received
+}
+
+func newSMTPSink(t *testing.T) *smtpSink {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &smtpSink{ln: ln}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *smtpSink) addr() string { return s.ln.Addr().String() }
+
+func (s *smtpSink) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+	reply("220 sink ESMTP")
+	var cur received
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.ToUpper(strings.TrimSpace(line))
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			reply("250 sink")
+		case strings.HasPrefix(cmd, "MAIL FROM:"):
+			cur = received{from: strings.Trim(strings.TrimSpace(line)[10:], "<>")}
+			reply("250 OK")
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			cur.to = append(cur.to, strings.Trim(strings.TrimSpace(line)[8:], "<>"))
+			reply("250 OK")
+		case cmd == "DATA":
+			reply("354 go ahead")
+			var data bytes.Buffer
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(l, "."))
+			}
+			msg, err := mail.ReadMessage(&data)
+			if err != nil {
+				reply("554 " + err.Error())
+				continue
+			}
+			cur.at, cur.msg = time.Now(), msg
+			s.mu.Lock()
+			s.msgs = append(s.msgs, cur)
+			s.mu.Unlock()
+			reply("250 queued")
+		case cmd == "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func (s *smtpSink) messages() []received {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]received(nil), s.msgs...)
+}
+
+func testConfig(addr string) Config {
+	return Config{SMTPAddr: addr, Message: eml.Config{
+		From: "Acme <news@acme.example>",
+		To:   "qa@acme.example",
+		Render: render.Options{
+			PhysicalAddress: "1 Main St, Springfield",
+			UnsubscribeURL:  "https://acme.example/unsubscribe",
+		},
+	}}
+}
+
+// testJourney has three emails an hour apart, given out of order.
+func testJourney() *journey.Journey {
+	return &journey.Journey{Emails: []journey.Email{
+		{Number: 2, Subject: "Second", Body: "Two", SendAfterHours: 1},
+		{Number: 1, Subject: "First", Body: "One"},
+		{Number: 3, Subject: "Third", Body: "Three", SendAfterHours: 2},
+	}}
+}
+
+func TestSend(t *testing.T) {
+	sink := newSMTPSink(t)
+	// An hour plays out in 100ms
+	const compression = 36000
+	start := time.Now()
+	results, err := Send(context.Background(), testJourney(), testConfig(sink.addr()), compression)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, res := range results {
+		if !res.OK || res.Number != i+1 || res.SentAt == nil {
+			t.Errorf("result %d = %+v", i, res)
+		}
+	}
+	msgs := sink.messages()
+	if len(msgs) != 3 {
+		t.Fatalf("sink received %d messages, want 3", len(msgs))
+	}
+
+	var firstDate time.Time
+	for i, want := range []string{"First", "Second", "Third"} {
+		m := msgs[i]
+		if got := m.msg.Header.Get("Subject"); got != want {
+			t.Errorf("message %d: Subject = %q, want %q", i, got, want)
+		}
+		if m.from != "news@acme.example" || len(m.to) != 1 || m.to[0] != "qa@acme.example" {
+			t.Errorf("message %d: envelope from %q to %q", i, m.from, m.to)
+		}
+		if got := m.msg.Header.Get("To"); got != "<qa@acme.example>" && got != "qa@acme.example" {
+			t.Errorf("message %d: To = %q", i, got)
+		}
+		if got := m.msg.Header.Get("List-Unsubscribe"); got != "<https://acme.example/unsubscribe>" {
+			t.Errorf("message %d: List-Unsubscribe = %q", i, got)
+		}
+		if !strings.HasPrefix(m.msg.Header.Get("Content-Type"), "multipart/alternative") {
+			t.Errorf("message %d: Content-Type = %q", i, m.msg.Header.Get("Content-Type"))
+		}
+
+		// Dates follow the live schedule; arrivals follow the compressed one
+		date, err := m.msg.Header.Date()
+		if err != nil {
+			t.Fatalf("message %d: %v", i, err)
+		}
+		if i == 0 {
+			firstDate = date
+		} else if got := date.Sub(firstDate); got != time.Duration(i)*time.Hour {
+			t.Errorf("message %d is dated %s after the first, want %dh", i, got, i)
+		}
+		wantAt := time.Duration(i) * time.Hour / compression
+		if got := m.at.Sub(start); got < wantAt || got > wantAt+500*time.Millisecond {
+			t.Errorf("message %d arrived after %s, want about %s", i, got, wantAt)
+		}
+	}
+}
+
+func TestSendTooSlow(t *testing.T) {
+	sink := newSMTPSink(t)
+	j := testJourney()
+	j.Emails[2].SendAfterHours, j.Emails[2].DayDelay = 48, 2
+
+	// Two days at 1000x is almost three minutes
+	results, err := Send(context.Background(), j, testConfig(sink.addr()), 1000)
+	if !errors.Is(err, ErrTooSlow) || results != nil {
+		t.Fatalf("Send = %v, %v; want ErrTooSlow", results, err)
+	}
+	if n := len(sink.messages()); n != 0 {
+		t.Errorf("sink received %d messages from a rejected send", n)
+	}
+
+	// Without compression the emails go out back to back
+	if _, err := Send(context.Background(), j, testConfig(sink.addr()), 0); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(sink.messages()); n != 3 {
+		t.Errorf("sink received %d messages, want 3", n)
+	}
+}
+
+func TestSendCancelled(t *testing.T) {
+	sink := newSMTPSink(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	// An hour takes a second, so only the first email goes out
+	results, err := Send(ctx, testJourney(), testConfig(sink.addr()), 3600)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want the context's error", err)
+	}
+	if len(results) != 3 || !results[0].OK || results[1].OK || results[2].OK {
+		t.Errorf("results = %+v", results)
+	}
+	if n := len(sink.messages()); n != 1 {
+		t.Errorf("sink received %d messages, want 1", n)
+	}
+}
+
+func TestWithRecipient(t *testing.T) {
+	cfg := testConfig("")
+	cfg.AllowedRecipients = []string{"dev@example.com", "@qa.acme.example"}
+	tests := []struct {
+		to      string
+		allowed bool
+	}{
+		{"qa@acme.example", true},
+		{"QA Team <QA@Acme.example>", true},
+		{"dev@example.com", true},
+		{"anyone@qa.acme.example", true},
+		{"victim@example.org", false},
+		{"someone@evilqa.acme.example", false},
+	}
+	for _, tt := range tests {
+		got, err := cfg.WithRecipient(tt.to)
+		switch {
+		case !tt.allowed && !errors.Is(err, ErrRecipientNotAllowed):
+			t.Errorf("WithRecipient(%q) = %v, want ErrRecipientNotAllowed", tt.to, err)
+		case tt.allowed && (err != nil || got.Message.To != tt.to):
+			t.Errorf("WithRecipient(%q) = %q, %v", tt.to, got.Message.To, err)
+		}
+	}
+	if _, err := cfg.WithRecipient("not an address"); err == nil || errors.Is(err, ErrRecipientNotAllowed) {
+		t.Errorf("WithRecipient of an invalid address = %v, want a parse error", err)
+	}
+
+	// Without an allow-list only the configured recipient is accepted
+	cfg.AllowedRecipients = nil
+	if _, err := cfg.WithRecipient("dev@example.com"); !errors.Is(err, ErrRecipientNotAllowed) {
+		t.Errorf("WithRecipient without an allow-list = %v", err)
+	}
+}
@@ -2,6 +2,7 @@ package validation
 
 import (
 	"strings"
+	"unicode"
 
 	"JourneyBuilder/internal/instruction"
 )
@@ -44,3 +45,64 @@ func (v *OutputValidator) ValidateResponse(response string, step instruction.Wor
 
 	return nil
 }
+
+// maxSubjectLength matches the subject line limit given to the model.
+const maxSubjectLength = 40
+
+// CheckEmail reviews one email of a journey before it is confirmed. Problems
+// must be fixed before confirming; warnings are advisory.
+func (v *OutputValidator) CheckEmail(subject, body string) (problems, warnings []string) {
+	subject = strings.TrimSpace(subject)
+	body = strings.TrimSpace(body)
+
+	if subject == "" {
+		problems = append(problems, "subject is empty")
+	}
+	if body == "" {
+		problems = append(problems, "body is empty")
+	}
+
+	lowerSubject := strings.ToLower(subject)
+	for _, keyword := range v.spamKeywords {
+		if strings.Contains(lowerSubject, keyword) {
+			problems = append(problems, "subject contains spam trigger \""+keyword+"\"")
+		}
+	}
+	if isShouting(subject) {
+		problems = append(problems, "subject is written in all caps")
+	}
+
+	// Same threshold as ValidateResponse
+	spamCount := 0
+	lowerBody := strings.ToLower(body)
+	for _, keyword := range v.spamKeywords {
+		if strings.Contains(lowerBody, keyword) {
+			spamCount++
+		}
+	}
+	if spamCount > 3 {
+		problems = append(problems, "body contains too many spam trigger phrases")
+	}
+
+	if len([]rune(subject)) > maxSubjectLength {
+		warnings = append(warnings, "subject is longer than 40 characters")
+	}
+	if strings.Count(subject, "!") > 1 {
+		warnings = append(warnings, "subject has more than one exclamation mark")
+	}
+	return problems, warnings
+}
+
+// isShouting reports whether s has several letters and all of them are upper case.
+func isShouting(s string) bool {
+	letters := 0
+	for _, r := range s {
+		if unicode.IsLetter(r) {
+			if !unicode.IsUpper(r) {
+				return false
+			}
+			letters++
+		}
+	}
+	return letters >= 8
+}